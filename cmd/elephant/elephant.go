@@ -15,6 +15,7 @@ import (
 
 	"github.com/abenz1267/elephant/v2/internal/comm"
 	"github.com/abenz1267/elephant/v2/internal/comm/client"
+	"github.com/abenz1267/elephant/v2/internal/comm/handlers"
 	"github.com/abenz1267/elephant/v2/internal/install"
 	"github.com/abenz1267/elephant/v2/internal/providers"
 	"github.com/abenz1267/elephant/v2/internal/util"
@@ -26,6 +27,9 @@ import (
 //go:embed version.txt
 var version string
 
+// time providers get to stop on shutdown
+const stopTimeout = 5 * time.Second
+
 func main() {
 	cmd := &cli.Command{
 		Name:                   "Elephant",
@@ -149,6 +153,24 @@ WantedBy=graphical-session.target
 					return nil
 				},
 			},
+			{
+				Name:  "dmenu",
+				Usage: "serves the lines of stdin as provider dmenu:<name> and prints the activated one",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "name of the provider, defaults to the connection id",
+					},
+					&cli.BoolFlag{
+						Name:  "identifier",
+						Usage: "print the identifier instead of the text",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					client.Dmenu(cmd.String("name"), cmd.Bool("identifier"))
+					return nil
+				},
+			},
 			{
 				Name:    "generatedoc",
 				Aliases: []string{"d"},
@@ -158,7 +180,16 @@ WantedBy=graphical-session.target
 						Name: "provider",
 					},
 				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "doc-format",
+						Value: util.DocFormatMarkdown,
+						Usage: "output format, markdown or json",
+					},
+				},
 				Action: func(ctx context.Context, cmd *cli.Command) error {
+					util.DocFormat = cmd.String("doc-format")
+
 					common.LoadGlobalConfig()
 
 					logger := slog.New(slog.DiscardHandler)
@@ -260,6 +291,74 @@ WantedBy=graphical-session.target
 					},
 				},
 			},
+			{
+				Name:  "provider",
+				Usage: "enable or disable providers at runtime",
+				Commands: []*cli.Command{
+					{
+						Name:  "enable",
+						Usage: "enables the given provider",
+						Arguments: []cli.Argument{
+							&cli.StringArg{
+								Name: "provider",
+							},
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							client.Control(handlers.ControlEnable, cmd.StringArg("provider"))
+
+							return nil
+						},
+					},
+					{
+						Name:  "disable",
+						Usage: "disables the given provider until it's enabled again or elephant restarts",
+						Arguments: []cli.Argument{
+							&cli.StringArg{
+								Name: "provider",
+							},
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							client.Control(handlers.ControlDisable, cmd.StringArg("provider"))
+
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "debug",
+				Usage: "inspect the running elephant",
+				Commands: []*cli.Command{
+					{
+						Name:  "dump",
+						Usage: "prints the internal state of the given provider as json",
+						Arguments: []cli.Argument{
+							&cli.StringArg{
+								Name: "provider",
+							},
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							client.Control(handlers.ControlDump, cmd.StringArg("provider"))
+
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "check",
+				Usage: "prints problems found in the configs, optionally only for the given provider",
+				Arguments: []cli.Argument{
+					&cli.StringArg{
+						Name: "provider",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					client.Control(handlers.ControlCheck, cmd.StringArg("provider"))
+
+					return nil
+				},
+			},
 			{
 				Name: "activate",
 				Arguments: []cli.Argument{
@@ -290,8 +389,31 @@ WantedBy=graphical-session.target
 				Aliases: []string{"d"},
 				Usage:   "enable debug logging",
 			},
+			&cli.BoolFlag{
+				Name:  "stats",
+				Usage: "print query statistics of the running elephant",
+			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if cmd.Bool("stats") {
+				logger := slog.New(slog.DiscardHandler)
+				slog.SetDefault(logger)
+
+				common.LoadGlobalConfig()
+
+				providers.Load(false)
+
+				names := []string{}
+
+				for k := range providers.Providers {
+					names = append(names, k)
+				}
+
+				client.Stats(names)
+
+				return nil
+			}
+
 			start := time.Now()
 
 			common.LoadGlobalConfig()
@@ -306,25 +428,53 @@ WantedBy=graphical-session.target
 
 			go func() {
 				<-signalChan
-				os.Remove(comm.Socket)
+
+				comm.Notify("STOPPING=1")
+
+				ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+
+				handlers.CancelQueries()
+				providers.Stop(ctx)
+				cancel()
+
+				comm.RemoveSocket()
 				os.Exit(0)
 			}()
 
+			level := slog.LevelInfo
+
 			if cmd.Bool("debug") {
-				logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-					Level: slog.LevelDebug,
-				}))
-				slog.SetDefault(logger)
+				level = slog.LevelDebug
 			}
 
+			// the wrapped handler accepts everything, filtering happens per provider.
+			logger := slog.New(common.NewLogHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+				Level: slog.LevelDebug,
+			}), level))
+			slog.SetDefault(logger)
+
 			common.InitRunPrefix()
 
 			runBeforeCommands()
 
+			providers.OnExternalUpdate = func(provider string) {
+				handlers.ProviderUpdated <- provider
+			}
+
 			providers.Load(true)
 
+			go providers.WatchConfig(func(provider string) {
+				handlers.ProviderUpdated <- provider
+			})
+
 			slog.Info("elephant", "startup", time.Since(start))
 
+			go func() {
+				providers.WaitSetup()
+				slog.Info("elephant", "ready", time.Since(start))
+				comm.Notify("READY=1")
+			}()
+
 			comm.StartListen()
 
 			return nil
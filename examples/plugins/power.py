@@ -0,0 +1,50 @@
+#!/usr/bin/env python3
+# Example external plugin for elephant, a simple power menu.
+#
+# elephant.toml:
+#
+#   [[plugins]]
+#   name = "power"
+#   name_pretty = "Power"
+#   icon = "system-shutdown"
+#   command = "python3 /path/to/power.py"
+
+import json
+import subprocess
+import sys
+
+ITEMS = [
+    {"identifier": "lock", "text": "Lock", "icon": "system-lock-screen", "command": ["loginctl", "lock-session"]},
+    {"identifier": "suspend", "text": "Suspend", "icon": "system-suspend", "command": ["systemctl", "suspend"]},
+    {"identifier": "reboot", "text": "Reboot", "icon": "system-reboot", "command": ["systemctl", "reboot"]},
+    {"identifier": "poweroff", "text": "Power Off", "icon": "system-shutdown", "command": ["systemctl", "poweroff"]},
+]
+
+
+def reply(res):
+    sys.stdout.write(json.dumps(res) + "\n")
+    sys.stdout.flush()
+
+
+for line in sys.stdin:
+    req = json.loads(line)
+    res = {"id": req["id"]}
+
+    if req["verb"] == "query":
+        # items without a score are fuzzy matched by elephant
+        res["items"] = [
+            {"identifier": i["identifier"], "text": i["text"], "icon": i["icon"], "actions": ["run"]}
+            for i in ITEMS
+        ]
+    elif req["verb"] == "activate":
+        item = next((i for i in ITEMS if i["identifier"] == req.get("identifier")), None)
+
+        if item is None:
+            res["error"] = "unknown item: %s" % req.get("identifier")
+        else:
+            subprocess.Popen(item["command"], start_new_session=True)
+    elif req["verb"] == "state":
+        res["states"] = []
+        res["actions"] = []
+
+    reply(res)
@@ -10,7 +10,9 @@ require (
 	github.com/fsnotify/fsnotify v1.9.0
 	github.com/mattn/go-sqlite3 v1.14.32
 	github.com/pelletier/go-toml/v2 v2.2.4
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/tinylib/msgp v1.4.0
+	golang.org/x/text v0.30.0
 	google.golang.org/protobuf v1.36.8
 )
 
@@ -42,7 +44,6 @@ require (
 	github.com/sergi/go-diff v1.4.0 // indirect
 	github.com/yalue/native_endian v1.0.2 // indirect
 	golang.org/x/crypto v0.43.0 // indirect
-	golang.org/x/text v0.30.0 // indirect
 )
 
 require (
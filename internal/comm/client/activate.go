@@ -5,7 +5,6 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
-	"net"
 	"strings"
 
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
@@ -27,7 +26,7 @@ func Activate(data string) {
 		panic(err)
 	}
 
-	conn, err := net.Dial("unix", socket)
+	conn, err := dial()
 	if err != nil {
 		panic(err)
 	}
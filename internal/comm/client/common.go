@@ -1,6 +1,115 @@
 package client
 
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
 const (
 	done  = 255
 	empty = 254
+	auth  = 7
 )
+
+var (
+	socket    string
+	secret    string
+	setupOnce sync.Once
+)
+
+// setup reads the socket settings from elephant.toml, if there is one.
+func setup() {
+	cfg := &common.ElephantConfig{}
+
+	if _, err := common.ProviderConfig("elephant"); err == nil {
+		if err := common.ReloadConfig("elephant", cfg); err != nil {
+			slog.Error("socket", "config", err)
+		}
+	}
+
+	socket = common.SocketPath(cfg)
+
+	var err error
+
+	secret, err = common.SocketSecret(cfg)
+	if err != nil {
+		slog.Error("socket", "secret", err)
+		os.Exit(1)
+	}
+}
+
+// dial connects to the socket and authenticates if a secret is configured.
+func dial() (net.Conn, error) {
+	setupOnce.Do(setup)
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == "" {
+		return conn, nil
+	}
+
+	if err := authenticate(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func authenticate(conn net.Conn) error {
+	b, err := json.Marshal(&pb.AuthRequest{Secret: secret})
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	buffer.Write([]byte{auth})
+	buffer.Write([]byte{1})
+
+	lengthBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
+	buffer.Write(lengthBuf)
+	buffer.Write(b)
+
+	if _, err := conn.Write(buffer.Bytes()); err != nil {
+		return err
+	}
+
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+
+	if header[0] != auth {
+		return fmt.Errorf("invalid protocol prefix")
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(header[1:5]))
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return err
+	}
+
+	resp := &pb.AuthResponse{}
+	if err := json.Unmarshal(payload, resp); err != nil {
+		return err
+	}
+
+	if resp.Error != "" {
+		return fmt.Errorf("auth: %s", resp.Error)
+	}
+
+	return nil
+}
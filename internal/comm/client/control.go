@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+func Control(command, provider string) {
+	req := pb.ControlRequest{
+		Command:  command,
+		Provider: provider,
+	}
+
+	b, err := json.Marshal(&req)
+	if err != nil {
+		panic(err)
+	}
+
+	conn, err := dial()
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	var buffer bytes.Buffer
+	buffer.Write([]byte{5})
+	buffer.Write([]byte{1})
+
+	lengthBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
+	buffer.Write(lengthBuf)
+	buffer.Write(b)
+
+	_, err = conn.Write(buffer.Bytes())
+	if err != nil {
+		panic(err)
+	}
+
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		panic(err)
+	}
+
+	if header[0] != 4 {
+		panic("invalid protocol prefix")
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(header[1:5]))
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		panic(err)
+	}
+
+	resp := &pb.ControlResponse{}
+	if err := json.Unmarshal(payload, resp); err != nil {
+		panic(err)
+	}
+
+	if resp.Error != "" {
+		fmt.Println(resp.Error)
+	}
+
+	if resp.Data != "" {
+		fmt.Println(resp.Data)
+	}
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+const dmenuResult = 8
+
+// Dmenu serves the lines of stdin as items and prints the activated one. A
+// line consists of tab separated fields: text, subtext, icon and identifier.
+// Exits with 1 if nothing was activated.
+func Dmenu(name string, identifier bool) {
+	req := pb.DmenuRequest{
+		Name: name,
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+
+		if fields[0] == "" {
+			continue
+		}
+
+		item := &pb.DmenuRequest_Item{
+			Text: fields[0],
+		}
+
+		if len(fields) > 1 {
+			item.Subtext = fields[1]
+		}
+
+		if len(fields) > 2 {
+			item.Icon = fields[2]
+		}
+
+		if len(fields) > 3 {
+			item.Identifier = fields[3]
+		}
+
+		req.Items = append(req.Items, item)
+	}
+
+	b, err := json.Marshal(&req)
+	if err != nil {
+		panic(err)
+	}
+
+	conn, err := dial()
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	var buffer bytes.Buffer
+	buffer.Write([]byte{8})
+	buffer.Write([]byte{1})
+
+	lengthBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
+	buffer.Write(lengthBuf)
+	buffer.Write(b)
+
+	_, err = conn.Write(buffer.Bytes())
+	if err != nil {
+		panic(err)
+	}
+
+	for {
+		header := make([]byte, 5)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			os.Exit(1)
+		}
+
+		payload := make([]byte, binary.BigEndian.Uint32(header[1:5]))
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			os.Exit(1)
+		}
+
+		if header[0] != dmenuResult {
+			continue
+		}
+
+		resp := &pb.DmenuResponse{}
+		if err := json.Unmarshal(payload, resp); err != nil {
+			panic(err)
+		}
+
+		if resp.Error != "" {
+			fmt.Fprintln(os.Stderr, resp.Error)
+			os.Exit(1)
+		}
+
+		// the first response only confirms the provider
+		if resp.Identifier == "" {
+			fmt.Fprintln(os.Stderr, resp.Provider)
+			continue
+		}
+
+		if identifier {
+			fmt.Println(resp.Identifier)
+		} else {
+			fmt.Println(resp.Text)
+		}
+
+		return
+	}
+}
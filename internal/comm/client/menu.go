@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
-	"net"
 
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
 )
@@ -19,7 +18,7 @@ func RequestMenu(menu string) {
 		panic(err)
 	}
 
-	conn, err := net.Dial("unix", socket)
+	conn, err := dial()
 	if err != nil {
 		panic(err)
 	}
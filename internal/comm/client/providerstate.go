@@ -8,7 +8,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net"
 
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
 )
@@ -23,7 +22,7 @@ func ProviderState(data string, j bool) {
 		panic(err)
 	}
 
-	conn, err := net.Dial("unix", socket)
+	conn, err := dial()
 	if err != nil {
 		panic(err)
 	}
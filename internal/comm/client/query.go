@@ -8,29 +8,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log/slog"
-	"net"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
 )
 
-var socket string
-
-func init() {
-	rd := os.Getenv("XDG_RUNTIME_DIR")
-
-	if rd == "" {
-		slog.Error("socket", "runtimedir", "XDG_RUNTIME_DIR not set. falling back to /tmp")
-		socket = filepath.Join(os.TempDir(), "elephant", "elephant.sock")
-	} else {
-		socket = filepath.Join(rd, "elephant", "elephant.sock")
-	}
-}
-
 func Query(data string, async, j bool) {
 	v := strings.Split(data, ";")
 	maxresults, _ := strconv.Atoi(v[2])
@@ -41,12 +24,18 @@ func Query(data string, async, j bool) {
 		Maxresults: int32(maxresults),
 	}
 
+	// no providers lets elephant pick them
+	if v[0] == "" {
+		req.Providers = nil
+		req.Combined = true
+	}
+
 	b, err := json.Marshal(&req)
 	if err != nil {
 		panic(err)
 	}
 
-	conn, err := net.Dial("unix", socket)
+	conn, err := dial()
 	if err != nil {
 		panic(err)
 	}
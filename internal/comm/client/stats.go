@@ -0,0 +1,98 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// Stats prints the query statistics of the given providers as a table.
+func Stats(providers []string) {
+	slices.Sort(providers)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tQUERIES\tP50\tP95\tLAST\tRESULTS")
+
+	for _, p := range providers {
+		s := providerStats(p)
+		if s == nil {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%d\n", p, s.Queries, us(s.P50Us), us(s.P95Us), us(s.LastUs), s.LastResults)
+	}
+
+	w.Flush()
+}
+
+func us(v int64) time.Duration {
+	return (time.Duration(v) * time.Microsecond).Round(10 * time.Microsecond)
+}
+
+func providerStats(provider string) *pb.ProviderStats {
+	req := pb.ProviderStateRequest{
+		Provider: provider,
+	}
+
+	b, err := json.Marshal(&req)
+	if err != nil {
+		panic(err)
+	}
+
+	conn, err := dial()
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	var buffer bytes.Buffer
+	buffer.Write([]byte{4})
+	buffer.Write([]byte{1})
+
+	lengthBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
+	buffer.Write(lengthBuf)
+	buffer.Write(b)
+
+	_, err = conn.Write(buffer.Bytes())
+	if err != nil {
+		panic(err)
+	}
+
+	reader := bufio.NewReader(conn)
+
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		panic(err)
+	}
+
+	// disabled or unknown providers only send done
+	if header[0] != 3 {
+		return nil
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(header[1:5]))
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		panic(err)
+	}
+
+	resp := &pb.ProviderStateResponse{}
+	if err := json.Unmarshal(payload, resp); err != nil {
+		panic(err)
+	}
+
+	if resp.Stats == nil {
+		return &pb.ProviderStats{}
+	}
+
+	return resp.Stats
+}
@@ -3,19 +3,24 @@ package comm
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
+	"sync"
+	"syscall"
 
 	"github.com/abenz1267/elephant/v2/internal/comm/handlers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
 )
 
 // connection id
 var (
 	cid    uint32
 	Socket string
+	secret string
 )
 
 var registry []MessageHandler
@@ -30,22 +35,17 @@ const (
 	SubscribeRequestHandlerPos = 2
 	MenuRequestHandlerPos      = 3
 	StateRequestHandlerPos     = 4
-	Protobuf                   = 0
-	JSON                       = 1
+	ControlRequestHandlerPos   = 5
+	PreviewRequestHandlerPos   = 6
+	AuthRequestPos             = 7
+	DmenuRequestHandlerPos     = 8
+	Multiplexed                = 0x80 // format flag, a request id follows the format byte
 )
 
-func init() {
-	rd := os.Getenv("XDG_RUNTIME_DIR")
-
-	if rd == "" {
-		slog.Error("socket", "runtimedir", "XDG_RUNTIME_DIR not set. falling back to /tmp")
-		Socket = filepath.Join(os.TempDir(), "elephant", "elephant.sock")
-	} else {
-		Socket = filepath.Join(rd, "elephant", "elephant.sock")
-	}
-
-	os.MkdirAll(filepath.Dir(Socket), 0o755)
+// max payload size accepted before the client authenticated, an AuthRequest is tiny
+const maxUnauthenticatedPayload = 4096
 
+func init() {
 	registry = make([]MessageHandler, 255)
 
 	registry[QueryRequestHandlerPos] = &handlers.QueryRequest{}
@@ -53,21 +53,51 @@ func init() {
 	registry[SubscribeRequestHandlerPos] = &handlers.SubscribeRequest{}
 	registry[MenuRequestHandlerPos] = &handlers.MenuRequest{}
 	registry[StateRequestHandlerPos] = &handlers.StateRequest{}
+	registry[ControlRequestHandlerPos] = &handlers.ControlRequest{}
+	registry[PreviewRequestHandlerPos] = &handlers.PreviewRequest{}
+	registry[DmenuRequestHandlerPos] = &handlers.DmenuRequest{}
 }
 
 func StartListen() {
-	os.Remove(Socket)
+	cfg := common.GetElephantConfig()
 
-	l, err := net.ListenUnix("unix", &net.UnixAddr{
-		Name: Socket,
-	})
+	Socket = common.SocketPath(cfg)
+
+	var err error
+
+	secret, err = common.SocketSecret(cfg)
 	if err != nil {
-		slog.Error("comm", "socket", err)
+		slog.Error("comm", "secret", err)
+		os.Exit(1)
+	}
+
+	l, err := activationListener()
+	if err != nil {
+		slog.Error("comm", "activation", err)
+		os.Exit(1)
+	}
+
+	if l != nil {
+		activated = true
+
+		if addr, ok := l.Addr().(*net.UnixAddr); ok && addr.Name != "" {
+			Socket = addr.Name
+		}
+
+		slog.Info("comm", "activation", Socket)
+	} else {
+		l, err = listen()
+		if err != nil {
+			slog.Error("comm", "socket", err)
+			os.Exit(1)
+		}
 	}
 	defer l.Close()
 
 	slog.Info("comm", "listen", "starting")
 
+	go Watchdog()
+
 	for {
 		conn, err := l.AcceptUnix()
 		if err != nil {
@@ -82,8 +112,63 @@ func StartListen() {
 	}
 }
 
+func listen() (*net.UnixListener, error) {
+	if err := socketDir(filepath.Dir(Socket)); err != nil {
+		return nil, err
+	}
+
+	os.Remove(Socket)
+
+	l, err := net.ListenUnix("unix", &net.UnixAddr{
+		Name: Socket,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(Socket, 0o600); err != nil {
+		slog.Error("comm", "socket", err)
+	}
+
+	return l, nil
+}
+
+// socketDir creates the socket's directory and restricts it to the user.
+// Shared directories like /tmp are left alone.
+func socketDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSticky != 0 {
+		slog.Warn("comm", "socketdir", "shared directory, not changing permissions", "dir", dir)
+		return nil
+	}
+
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is owned by another user", dir)
+	}
+
+	if info.Mode().Perm() != 0o700 {
+		return os.Chmod(dir, 0o700)
+	}
+
+	return nil
+}
+
 func handle(conn net.Conn, cid uint32) {
 	defer conn.Close()
+	defer handlers.ConnectionClosed(cid)
+
+	authenticated := secret == ""
+
+	// responses of concurrent requests must not interleave
+	var writeMut sync.Mutex
 
 	for {
 		tb := make([]byte, 1)
@@ -110,6 +195,24 @@ func handle(conn net.Conn, cid uint32) {
 
 		format := uint8(fb[0])
 
+		var id uint32
+
+		multiplexed := format&Multiplexed != 0
+
+		if multiplexed {
+			format &^= Multiplexed
+
+			ib := make([]byte, 4)
+			if _, err := io.ReadFull(conn, ib); err != nil {
+				slog.Error("conn", "readid", err)
+				continue
+			}
+
+			id = binary.BigEndian.Uint32(ib)
+		}
+
+		rc := handlers.NewRequestConn(conn, &writeMut, id, multiplexed)
+
 		lb := make([]byte, 4)
 		if _, err := io.ReadFull(conn, lb); err != nil {
 			slog.Error("conn", "readlength", err)
@@ -118,12 +221,35 @@ func handle(conn net.Conn, cid uint32) {
 
 		l := binary.BigEndian.Uint32(lb)
 
+		if !authenticated && l > maxUnauthenticatedPayload {
+			slog.Warn("comm", "auth", "payload too large for an unauthenticated request", "length", l)
+			break
+		}
+
 		p := make([]byte, l)
 		if _, err := io.ReadFull(conn, p); err != nil {
 			slog.Error("conn", "readpayload", err)
 			continue
 		}
 
-		go registry[mType].Handle(format, cid, conn, p)
+		if mType == AuthRequestPos {
+			if !handlers.Authenticate(format, rc, p, secret) {
+				slog.Warn("comm", "auth", "invalid secret")
+				break
+			}
+
+			authenticated = true
+
+			continue
+		}
+
+		if !authenticated {
+			slog.Warn("comm", "auth", "unauthenticated request", "type", mType)
+			handlers.RejectUnauthenticated(format, mType == ActivateRequestHandlerPos, rc)
+
+			break
+		}
+
+		go registry[mType].Handle(format, cid, rc, p)
 	}
 }
@@ -0,0 +1,135 @@
+package comm
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHandleLimitsUnauthenticatedPayload(t *testing.T) {
+	secret = "secret"
+
+	t.Cleanup(func() {
+		secret = ""
+	})
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	done := make(chan struct{})
+
+	go func() {
+		handle(server, 1)
+		close(done)
+	}()
+
+	header := []byte{QueryRequestHandlerPos, 1}
+	header = binary.BigEndian.AppendUint32(header, 1<<30)
+
+	go client.Write(header)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection kept open for an oversized unauthenticated payload")
+	}
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+
+	if _, err := client.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("got %v, want a closed connection", err)
+	}
+}
+
+func TestSocketDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "elephant")
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := socketDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, _ := os.Stat(dir); info.Mode().Perm() != 0o700 {
+		t.Errorf("got %s, want the directory restricted to the user", info.Mode())
+	}
+
+	shared := t.TempDir()
+
+	if err := os.Chmod(shared, 0o777|os.ModeSticky); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := socketDir(shared); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, _ := os.Stat(shared); info.Mode().Perm() != 0o777 || info.Mode()&os.ModeSticky == 0 {
+		t.Errorf("got %s, shared directory was changed", info.Mode())
+	}
+}
+
+// serve accepts connections on a new socket until the test ends.
+func serve(t *testing.T, accept bool) {
+	t.Helper()
+
+	prev := Socket
+	Socket = filepath.Join(t.TempDir(), "elephant.sock")
+
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: Socket, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		l.Close()
+		Socket = prev
+	})
+
+	if !accept {
+		return
+	}
+
+	go func() {
+		for {
+			conn, err := l.AcceptUnix()
+			if err != nil {
+				return
+			}
+
+			go handle(conn, 1)
+		}
+	}()
+}
+
+func TestProbe(t *testing.T) {
+	secret = "secret"
+
+	t.Cleanup(func() {
+		secret = ""
+	})
+
+	serve(t, true)
+
+	if err := probe(time.Second); err != nil {
+		t.Errorf("got %v for a responsive socket", err)
+	}
+
+	serve(t, false)
+
+	if err := probe(100 * time.Millisecond); err == nil {
+		t.Error("no error for a socket not accepting connections")
+	}
+
+	Socket = filepath.Join(t.TempDir(), "missing.sock")
+
+	if err := probe(100 * time.Millisecond); err == nil {
+		t.Error("no error for a missing socket")
+	}
+}
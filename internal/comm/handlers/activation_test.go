@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+func activate(t *testing.T, req *pb.ActivateRequest) {
+	t.Helper()
+
+	b, err := marshal(FormatJSON, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, r := pipe(t)
+
+	wg := write(t, func() {
+		(&ActivateRequest{}).Handle(FormatJSON, 1, conn, b)
+	})
+
+	if f := readFrame(t, r, false); f.t != ActivationFinished {
+		t.Fatalf("got type %d, want %d", f.t, ActivationFinished)
+	}
+
+	wg.Wait()
+}
+
+func TestActivationDoesNotRecordUsage(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	addProvider(t, "usage", nil)
+
+	activated := 0
+
+	p := providers.Providers["usage"]
+	p.Activate = func(_ bool, _, _, _, _ string, _ uint8, _ net.Conn) {
+		activated++
+	}
+	providers.Providers["usage"] = p
+
+	activate(t, &pb.ActivateRequest{Provider: "usage", Identifier: "item", Action: "remove"})
+
+	if activated != 1 {
+		t.Fatalf("provider activated %d times, want 1", activated)
+	}
+
+	if score := common.UsageScore("usage", "item"); score != 0 {
+		t.Errorf("got usage score %d, usage is up to the provider", score)
+	}
+}
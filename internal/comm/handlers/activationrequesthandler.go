@@ -1,36 +1,56 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/binary"
-	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/common/history"
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
-	"google.golang.org/protobuf/proto"
 )
 
+// a client that went away must not block the handler
+const activationWriteTimeout = time.Second
+
 type ActivateRequest struct{}
 
+// ActivationResult is sent as payload of the ActivationFinished frame for json based formats, if
+// requested. Protobuf clients receive a pb.ActivateResponse, which is empty for a plain success.
+type ActivationResult struct {
+	Ok      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Status  int32  `json:"status"`
+	Message string `json:"message,omitempty"`
+	Payload string `json:"payload,omitempty"`
+}
+
 func (a *ActivateRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
 	req := &pb.ActivateRequest{}
 
-	switch format {
-	case 0:
-		if err := proto.Unmarshal(data, req); err != nil {
-			slog.Error("activationrequesthandler", "protobuf", err)
+	if err := unmarshal(format, data, req); err != nil {
+		slog.Error("activationrequesthandler", "unmarshal", err)
 
-			return
-		}
-	case 1:
-		if err := json.Unmarshal(data, req); err != nil {
-			slog.Error("activationrequesthandler", "protobuf", err)
+		return
+	}
 
-			return
-		}
+	common.StartActivation(conn)
+	defer common.EndActivation(conn)
+
+	result := wantsResult(format, req)
+	item := lookupItem(req.Provider, req.Identifier)
+
+	if t, ok := dedupRoute(req.Provider, req.Identifier, req.Action); ok {
+		req.Provider = t.provider
+		req.Identifier = t.identifier
+	}
+
+	if strings.HasPrefix(req.Provider, dmenuPrefix) {
+		activateDmenu(format, result, req, conn)
+		return
 	}
 
 	provider := req.Provider
@@ -39,19 +59,92 @@ func (a *ActivateRequest) Handle(format uint8, cid uint32, conn net.Conn, data [
 		provider = strings.Split(provider, ":")[0]
 	}
 
-	if p, ok := providers.Providers[provider]; ok {
-		p.Activate(req.Single, req.Identifier, req.Action, req.Query, req.Arguments, format, conn)
+	p, ok := providers.Providers[provider]
+	if !ok {
+		if format != FormatProtobuf {
+			writeActivationFinished(format, result, failedResult(fmt.Sprintf("unknown provider: %s", req.Provider)), conn)
+		}
+
+		return
+	}
+
+	if !providers.Enabled(provider) {
+		writeActivationFinished(format, result, failedResult(fmt.Sprintf("provider disabled: %s", req.Provider)), conn)
+		return
+	}
+
+	if req.Action == common.ActionResetUsage {
+		common.ResetUsage(req.Provider)
+		writeActivationFinished(format, result, &ActivationResult{Ok: true}, conn)
+		return
+	}
+
+	p.Activate(req.Single, req.Identifier, req.Action, req.Query, req.Arguments, format, conn)
+
+	res, reported := common.TakeActivationResult(conn)
+
+	if reported && res.Status != common.ActivationOk {
+		r := failedResult(res.Message)
+		r.Status = res.Status
+		r.Payload = res.Payload
+
+		writeActivationFinished(format, result, r, conn)
+		return
+	}
+
+	if req.Action != history.ActionDelete {
+		common.NotifyActivation(common.Activation{
+			Provider:   req.Provider,
+			Identifier: req.Identifier,
+			Action:     req.Action,
+			Query:      req.Query,
+			Arguments:  req.Arguments,
+			Text:       item.text,
+			Subtext:    item.subtext,
+			Icon:       item.icon,
+		})
+	}
 
-		var buffer bytes.Buffer
-		buffer.Write([]byte{ActivationFinished})
+	writeActivationFinished(format, result, &ActivationResult{
+		Ok:      true,
+		Message: res.Message,
+		Payload: res.Payload,
+	}, conn)
+}
+
+func failedResult(msg string) *ActivationResult {
+	return &ActivationResult{
+		Error:   msg,
+		Status:  common.ActivationFailed,
+		Message: msg,
+	}
+}
 
-		lengthBuf := make([]byte, 4)
-		binary.BigEndian.PutUint32(lengthBuf, uint32(0))
-		buffer.Write(lengthBuf)
+// wantsResult checks if the activation finished response should carry the result. Older clients
+// expect it to be empty, so it has to be requested, except for jsonlines.
+func wantsResult(format uint8, req *pb.ActivateRequest) bool {
+	return req.Result || format == FormatJSONLines
+}
+
+func writeActivationFinished(format uint8, result bool, res *ActivationResult, conn net.Conn) {
+	var payload any
 
-		_, err := conn.Write(buffer.Bytes())
-		if err != nil {
-			slog.Debug("activation done", "write", err)
+	switch {
+	case !result:
+	case format == FormatProtobuf:
+		if res.Status != common.ActivationOk || res.Message != "" || res.Payload != "" {
+			payload = &pb.ActivateResponse{
+				Error:   res.Error,
+				Status:  res.Status,
+				Message: res.Message,
+				Payload: res.Payload,
+			}
 		}
+	default:
+		payload = res
+	}
+
+	if err := writeFrameTimeout(format, ActivationFinished, payload, conn, activationWriteTimeout); err != nil {
+		slog.Debug("activation done", "write", err)
 	}
 }
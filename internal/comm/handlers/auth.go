@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+const errAuthRequired = "authentication required"
+
+// Authenticate checks the secret of an AuthRequest and writes the result.
+func Authenticate(format uint8, conn net.Conn, data []byte, secret string) bool {
+	req := &pb.AuthRequest{}
+
+	if err := unmarshal(format, data, req); err != nil {
+		slog.Error("auth", "unmarshal", err)
+
+		return false
+	}
+
+	res := &pb.AuthResponse{}
+
+	ok := secret == "" || subtle.ConstantTimeCompare([]byte(req.Secret), []byte(secret)) == 1
+	if !ok {
+		res.Error = "invalid secret"
+	}
+
+	if err := writeFrame(format, AuthResult, res, conn); err != nil {
+		slog.Error("auth", "write", err)
+		return false
+	}
+
+	return ok
+}
+
+// RejectUnauthenticated answers a request sent before authenticating.
+// Activations get a failed activation result, so clients waiting for one see the error. Secrets are
+// newer than the activation result, so it's always sent.
+func RejectUnauthenticated(format uint8, activation bool, conn net.Conn) {
+	if activation {
+		writeActivationFinished(format, true, failedResult(errAuthRequired), conn)
+		return
+	}
+
+	res := &pb.AuthResponse{
+		Error: errAuthRequired,
+	}
+
+	if err := writeFrame(format, AuthResult, res, conn); err != nil {
+		slog.Error("auth", "write", err)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// routeCombined picks the providers for a combined query. A configured prefix
+// routes to its provider only, otherwise all enabled providers are queried.
+func routeCombined(query string) ([]string, string) {
+	prefix := ""
+
+	for k, v := range common.GetElephantConfig().QueryPrefixes {
+		if len(k) <= len(prefix) || !strings.HasPrefix(query, k) {
+			continue
+		}
+
+		if _, ok := providers.Providers[v]; ok && providers.Enabled(v) {
+			prefix = k
+		}
+	}
+
+	if prefix != "" {
+		return []string{common.GetElephantConfig().QueryPrefixes[prefix]}, strings.TrimPrefix(query, prefix)
+	}
+
+	res := []string{}
+
+	for k := range providers.Providers {
+		if providers.Enabled(k) {
+			res = append(res, k)
+		}
+	}
+
+	slices.Sort(res)
+
+	return res, query
+}
+
+// interleave takes items of all providers in turn, keeping each provider's order.
+// Used for empty combined queries, where scores don't tell much.
+func interleave(entries []*pb.QueryResponse_Item) []*pb.QueryResponse_Item {
+	order := []string{}
+	byProvider := make(map[string][]*pb.QueryResponse_Item)
+
+	for _, v := range entries {
+		if _, ok := byProvider[v.Provider]; !ok {
+			order = append(order, v.Provider)
+		}
+
+		byProvider[v.Provider] = append(byProvider[v.Provider], v)
+	}
+
+	res := make([]*pb.QueryResponse_Item, 0, len(entries))
+
+	for i := 0; len(res) < len(entries); i++ {
+		for _, p := range order {
+			if i < len(byProvider[p]) {
+				res = append(res, byProvider[p][i])
+			}
+		}
+	}
+
+	return res
+}
+
+type dedupTarget struct {
+	provider   string
+	identifier string
+}
+
+// max amount of remembered absorbed actions before starting over
+const maxDedupRoutes = 1000
+
+var (
+	dedupMut    sync.Mutex
+	dedupRoutes = make(map[string]dedupTarget)
+)
+
+func dedupRouteKey(provider, identifier, action string) string {
+	return provider + "\x00" + identifier + "\x00" + action
+}
+
+// dedup drops items sharing a DedupKey with a higher scoring item, which absorbs
+// their actions. Entries must be sorted. Activating an absorbed action is routed
+// back to the item it came from.
+func dedup(entries []*pb.QueryResponse_Item) []*pb.QueryResponse_Item {
+	seen := make(map[string]int)
+	cloned := make(map[string]bool)
+	res := make([]*pb.QueryResponse_Item, 0, len(entries))
+
+	dedupMut.Lock()
+	defer dedupMut.Unlock()
+
+	for _, v := range entries {
+		if v.DedupKey == "" {
+			res = append(res, v)
+			continue
+		}
+
+		i, ok := seen[v.DedupKey]
+		if !ok {
+			seen[v.DedupKey] = len(res)
+			res = append(res, v)
+			continue
+		}
+
+		// providers can cache their items, so the winner is copied before changing it
+		if !cloned[v.DedupKey] {
+			res[i] = proto.Clone(res[i]).(*pb.QueryResponse_Item)
+			cloned[v.DedupKey] = true
+		}
+
+		winner := res[i]
+
+		for _, a := range v.Actions {
+			if slices.Contains(winner.Actions, a) {
+				continue
+			}
+
+			winner.Actions = append(winner.Actions, a)
+
+			if len(dedupRoutes) >= maxDedupRoutes {
+				clear(dedupRoutes)
+			}
+
+			dedupRoutes[dedupRouteKey(winner.Provider, winner.Identifier, a)] = dedupTarget{
+				provider:   v.Provider,
+				identifier: v.Identifier,
+			}
+		}
+	}
+
+	return res
+}
+
+// dedupSent deduplicates the entries and drops the ones sharing a DedupKey with an
+// already streamed item, which can't absorb their actions anymore. Entries must be sorted.
+func dedupSent(entries []*pb.QueryResponse_Item, sent map[string]bool) []*pb.QueryResponse_Item {
+	res := []*pb.QueryResponse_Item{}
+
+	for _, v := range dedup(entries) {
+		if v.DedupKey != "" {
+			if sent[v.DedupKey] {
+				continue
+			}
+
+			sent[v.DedupKey] = true
+		}
+
+		res = append(res, v)
+	}
+
+	return res
+}
+
+// dedupRoute returns where an absorbed action has to be activated.
+func dedupRoute(provider, identifier, action string) (dedupTarget, bool) {
+	dedupMut.Lock()
+	defer dedupMut.Unlock()
+
+	t, ok := dedupRoutes[dedupRouteKey(provider, identifier, action)]
+
+	return t, ok
+}
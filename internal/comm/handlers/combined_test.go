@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+func dedupItem(provider, identifier, key string, actions ...string) *pb.QueryResponse_Item {
+	return &pb.QueryResponse_Item{
+		Provider:   provider,
+		Identifier: identifier,
+		DedupKey:   key,
+		Actions:    actions,
+	}
+}
+
+func TestDedupMergesActions(t *testing.T) {
+	app := dedupItem("desktopapplications", "firefox.desktop", "/usr/bin/firefox", "start", "new_window")
+	run := dedupItem("runner", "firefox", "/usr/bin/firefox", "run", "start")
+	term := dedupItem("runner", "firefox-term", "/usr/bin/firefox", "runterminal", "run")
+	other := dedupItem("runner", "htop", "/usr/bin/htop", "run")
+	plain := dedupItem("calc", "1", "", "copy")
+
+	res := dedup([]*pb.QueryResponse_Item{app, run, plain, term, other})
+
+	if got := fmt.Sprint(identifiers(res)); got != "[firefox.desktop 1 htop]" {
+		t.Fatalf("got %s, want the higher scoring item of each key", got)
+	}
+
+	if want := []string{"start", "new_window", "run", "runterminal"}; !slices.Equal(res[0].Actions, want) {
+		t.Errorf("got actions %v, want %v", res[0].Actions, want)
+	}
+
+	if !slices.Equal(app.Actions, []string{"start", "new_window"}) {
+		t.Errorf("original item was changed: %v", app.Actions)
+	}
+
+	if !slices.Equal(res[2].Actions, []string{"run"}) {
+		t.Errorf("item without duplicates got actions %v", res[2].Actions)
+	}
+
+	routes := []struct {
+		action string
+		want   string
+	}{
+		{"run", "runner/firefox"},
+		{"runterminal", "runner/firefox-term"},
+		{"start", ""},
+		{"new_window", ""},
+	}
+
+	for _, tt := range routes {
+		got := ""
+
+		if r, ok := dedupRoute("desktopapplications", "firefox.desktop", tt.action); ok {
+			got = r.provider + "/" + r.identifier
+		}
+
+		if got != tt.want {
+			t.Errorf("%s: routed to %q, want %q", tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestDedupSent(t *testing.T) {
+	sent := make(map[string]bool)
+
+	first := dedupSent([]*pb.QueryResponse_Item{
+		dedupItem("a", "a1", "k1", "x"),
+		dedupItem("a", "a2", "", "x"),
+	}, sent)
+
+	second := dedupSent([]*pb.QueryResponse_Item{
+		dedupItem("b", "b1", "k1", "y"),
+		dedupItem("b", "b2", "k2", "y"),
+		dedupItem("b", "b3", "k2", "z"),
+	}, sent)
+
+	if got := fmt.Sprint(identifiers(first)); got != "[a1 a2]" {
+		t.Errorf("first: got %s", got)
+	}
+
+	if got := fmt.Sprint(identifiers(second)); got != "[b2]" {
+		t.Errorf("second: got %s, want already sent keys dropped", got)
+	}
+
+	if !slices.Equal(second[0].Actions, []string{"y", "z"}) {
+		t.Errorf("got actions %v, want merged within the batch", second[0].Actions)
+	}
+}
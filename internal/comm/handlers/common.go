@@ -2,17 +2,166 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// wire formats a client can pick per request
+const (
+	FormatProtobuf  = 0
+	FormatJSON      = 1
+	FormatJSONLines = 2
 )
 
-func writeStatus(status int, conn net.Conn) (bool, error) {
+// jsonLine is the envelope used for FormatJSONLines. Every response is
+// written as a single line, so scripts can read the socket with f.e. `jq`.
+type jsonLine struct {
+	Type int     `json:"type"`
+	ID   *uint32 `json:"id,omitempty"`
+	Data any     `json:"data,omitempty"`
+}
+
+// requestConn is the connection handed to handlers and providers for a single
+// request. Writes of all requests on a connection share a mutex, multiplexed
+// requests additionally echo their id on every response.
+type requestConn struct {
+	net.Conn
+	mut         *sync.Mutex
+	id          uint32
+	multiplexed bool
+}
+
+func NewRequestConn(conn net.Conn, mut *sync.Mutex, id uint32, multiplexed bool) net.Conn {
+	return &requestConn{
+		Conn:        conn,
+		mut:         mut,
+		id:          id,
+		multiplexed: multiplexed,
+	}
+}
+
+func (c *requestConn) Write(b []byte) (int, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	return c.Conn.Write(b)
+}
+
+// requestID returns the id of a multiplexed request and whether there is one.
+func requestID(conn net.Conn) (uint32, bool) {
+	if rc, ok := conn.(*requestConn); ok && rc.multiplexed {
+		return rc.id, true
+	}
+
+	return 0, false
+}
+
+func unmarshal(format uint8, data []byte, msg proto.Message) error {
+	switch format {
+	case FormatProtobuf:
+		return proto.Unmarshal(data, msg)
+	default:
+		return json.Unmarshal(data, msg)
+	}
+}
+
+func marshal(format uint8, msg any) ([]byte, error) {
+	if format == FormatProtobuf {
+		if m, ok := msg.(proto.Message); ok {
+			return proto.Marshal(m)
+		}
+	}
+
+	return json.Marshal(msg)
+}
+
+// writeFrame writes a single response. For protobuf and json the payload is
+// prefixed with the type byte, the request id for multiplexed requests and a
+// 4 byte big-endian length. For jsonlines it is wrapped in a jsonLine envelope
+// and terminated by a newline.
+func writeFrame(format uint8, t int, msg any, conn net.Conn) error {
 	var buffer bytes.Buffer
-	buffer.Write([]byte{byte(status)})
+
+	id, multiplexed := requestID(conn)
+
+	if format == FormatJSONLines {
+		line := jsonLine{Type: t, Data: msg}
+
+		if multiplexed {
+			line.ID = &id
+		}
+
+		b, err := json.Marshal(line)
+		if err != nil {
+			return err
+		}
+
+		buffer.Write(b)
+		buffer.WriteByte('\n')
+
+		_, err = conn.Write(buffer.Bytes())
+		return err
+	}
+
+	var b []byte
+
+	if msg != nil {
+		var err error
+
+		b, err = marshal(format, msg)
+		if err != nil {
+			return err
+		}
+	}
+
+	buffer.Write([]byte{byte(t)})
+
+	if multiplexed {
+		idBuf := make([]byte, 4)
+		binary.BigEndian.PutUint32(idBuf, id)
+		buffer.Write(idBuf)
+	}
 
 	lengthBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
 	buffer.Write(lengthBuf)
+	buffer.Write(b)
 
 	_, err := conn.Write(buffer.Bytes())
+	return err
+}
+
+var errWriteTimeout = errors.New("write timed out")
+
+// writeFrameTimeout writes like writeFrame, but stops waiting for the write
+// after timeout. The connection can be shared by multiplexed requests, so its
+// deadline can't be used. The write itself is finished in the background.
+func writeFrameTimeout(format uint8, t int, msg any, conn net.Conn, timeout time.Duration) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- writeFrame(format, t, msg, conn)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return errWriteTimeout
+	}
+}
+
+func writeStatus(format uint8, status int, conn net.Conn) (bool, error) {
+	err := writeFrame(format, status, nil, conn)
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+type frame struct {
+	t       int
+	id      uint32
+	payload []byte
+}
+
+// pipe returns a connection for the server side and a reader for the client side.
+func pipe(t *testing.T) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	server, client := net.Pipe()
+
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+
+	return server, bufio.NewReader(client)
+}
+
+func readFrame(t *testing.T, r io.Reader, multiplexed bool) frame {
+	t.Helper()
+
+	var f frame
+
+	tb := make([]byte, 1)
+	if _, err := io.ReadFull(r, tb); err != nil {
+		t.Fatal(err)
+	}
+
+	f.t = int(tb[0])
+
+	if multiplexed {
+		ib := make([]byte, 4)
+		if _, err := io.ReadFull(r, ib); err != nil {
+			t.Fatal(err)
+		}
+
+		f.id = binary.BigEndian.Uint32(ib)
+	}
+
+	lb := make([]byte, 4)
+	if _, err := io.ReadFull(r, lb); err != nil {
+		t.Fatal(err)
+	}
+
+	f.payload = make([]byte, binary.BigEndian.Uint32(lb))
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+func readLine(t *testing.T, r *bufio.Reader) map[string]json.RawMessage {
+	t.Helper()
+
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := map[string]json.RawMessage{}
+
+	if err := json.Unmarshal(line, &res); err != nil {
+		t.Fatal(err)
+	}
+
+	return res
+}
+
+// write runs fn in the background, as writes to a pipe block until they are read.
+func write(t *testing.T, fn func()) *sync.WaitGroup {
+	t.Helper()
+
+	var wg sync.WaitGroup
+
+	wg.Go(fn)
+
+	return &wg
+}
+
+func testResponse() *pb.QueryResponse {
+	return &pb.QueryResponse{
+		Query: "fire",
+		Qid:   3,
+		Item: &pb.QueryResponse_Item{
+			Identifier: "firefox.desktop",
+			Text:       "Firefox",
+			Provider:   "desktopapplications",
+			Score:      120,
+			Actions:    []string{"start", "pin"},
+			Fuzzyinfo: &pb.QueryResponse_Item_FuzzyInfo{
+				Field:     "text",
+				Positions: []int32{0, 1, 2, 3},
+			},
+		},
+	}
+}
+
+func TestWriteFrameRoundTrip(t *testing.T) {
+	for _, format := range []uint8{FormatProtobuf, FormatJSON} {
+		conn, r := pipe(t)
+		msg := testResponse()
+
+		wg := write(t, func() {
+			if err := writeFrame(format, QueryItem, msg, conn); err != nil {
+				t.Error(err)
+			}
+		})
+
+		f := readFrame(t, r, false)
+		wg.Wait()
+
+		if f.t != QueryItem {
+			t.Errorf("format %d: type %d, want %d", format, f.t, QueryItem)
+		}
+
+		res := &pb.QueryResponse{}
+
+		if err := unmarshal(format, f.payload, res); err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+
+		if !proto.Equal(res, msg) {
+			t.Errorf("format %d: got %v, want %v", format, res, msg)
+		}
+	}
+}
+
+func TestWriteFrameJSONLines(t *testing.T) {
+	conn, r := pipe(t)
+	msg := testResponse()
+
+	wg := write(t, func() {
+		if err := writeFrame(FormatJSONLines, QueryItem, msg, conn); err != nil {
+			t.Error(err)
+		}
+	})
+
+	line := readLine(t, r)
+	wg.Wait()
+
+	if string(line["type"]) != "0" {
+		t.Errorf("type %s, want 0", line["type"])
+	}
+
+	if _, ok := line["id"]; ok {
+		t.Error("id set for a request without id")
+	}
+
+	res := &pb.QueryResponse{}
+
+	if err := json.Unmarshal(line["data"], res); err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(res, msg) {
+		t.Errorf("got %v, want %v", res, msg)
+	}
+}
+
+func TestWriteFrameMultiplexed(t *testing.T) {
+	server, r := pipe(t)
+	conn := NewRequestConn(server, &sync.Mutex{}, 42, true)
+
+	wg := write(t, func() {
+		writeFrame(FormatJSON, QueryDone, nil, conn)
+		writeFrame(FormatJSONLines, QueryDone, nil, conn)
+	})
+
+	f := readFrame(t, r, true)
+
+	if f.t != QueryDone || f.id != 42 || len(f.payload) != 0 {
+		t.Errorf("got %+v, want type %d, id 42 and no payload", f, QueryDone)
+	}
+
+	line := readLine(t, r)
+	wg.Wait()
+
+	if string(line["id"]) != "42" {
+		t.Errorf("jsonlines id %s, want 42", line["id"])
+	}
+}
+
+func TestUnmarshalRequest(t *testing.T) {
+	req := &pb.ActivateRequest{
+		Provider:   "clipboard",
+		Identifier: "abc",
+		Action:     "copy",
+		Result:     true,
+	}
+
+	for _, format := range []uint8{FormatProtobuf, FormatJSON, FormatJSONLines} {
+		b, err := marshal(format, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res := &pb.ActivateRequest{}
+
+		if err := unmarshal(format, b, res); err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+
+		if !proto.Equal(res, req) {
+			t.Errorf("format %d: got %v, want %v", format, res, req)
+		}
+	}
+}
+
+func TestActivationFinished(t *testing.T) {
+	res := &ActivationResult{
+		Ok:      true,
+		Payload: "1234",
+	}
+
+	tests := []struct {
+		name   string
+		format uint8
+		req    *pb.ActivateRequest
+		want   *ActivationResult
+	}{
+		{"json without result", FormatJSON, &pb.ActivateRequest{}, nil},
+		{"protobuf without result", FormatProtobuf, &pb.ActivateRequest{}, nil},
+		{"json with result", FormatJSON, &pb.ActivateRequest{Result: true}, res},
+		{"protobuf with result", FormatProtobuf, &pb.ActivateRequest{Result: true}, res},
+	}
+
+	for _, tt := range tests {
+		conn, r := pipe(t)
+
+		wg := write(t, func() {
+			writeActivationFinished(tt.format, wantsResult(tt.format, tt.req), res, conn)
+		})
+
+		f := readFrame(t, r, false)
+		wg.Wait()
+
+		if f.t != ActivationFinished {
+			t.Errorf("%s: type %d, want %d", tt.name, f.t, ActivationFinished)
+		}
+
+		if tt.want == nil {
+			if len(f.payload) != 0 {
+				t.Errorf("%s: payload %q, want none", tt.name, f.payload)
+			}
+
+			continue
+		}
+
+		switch tt.format {
+		case FormatProtobuf:
+			got := &pb.ActivateResponse{}
+
+			if err := proto.Unmarshal(f.payload, got); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Payload != tt.want.Payload {
+				t.Errorf("%s: payload %q, want %q", tt.name, got.Payload, tt.want.Payload)
+			}
+		default:
+			got := &ActivationResult{}
+
+			if err := json.Unmarshal(f.payload, got); err != nil {
+				t.Fatal(err)
+			}
+
+			if *got != *tt.want {
+				t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+			}
+		}
+	}
+
+	conn, r := pipe(t)
+
+	wg := write(t, func() {
+		writeActivationFinished(FormatJSONLines, wantsResult(FormatJSONLines, &pb.ActivateRequest{}), res, conn)
+	})
+
+	line := readLine(t, r)
+	wg.Wait()
+
+	got := &ActivationResult{}
+
+	if err := json.Unmarshal(line["data"], got); err != nil {
+		t.Fatal(err)
+	}
+
+	if *got != *res {
+		t.Errorf("jsonlines: got %+v, want %+v", got, res)
+	}
+}
+
+// deadlineConn records if a deadline was set on the connection.
+type deadlineConn struct {
+	net.Conn
+	deadline bool
+}
+
+func (c *deadlineConn) SetDeadline(t time.Time) error {
+	c.deadline = true
+	return c.Conn.SetDeadline(t)
+}
+
+func (c *deadlineConn) SetWriteDeadline(t time.Time) error {
+	c.deadline = true
+	return c.Conn.SetWriteDeadline(t)
+}
+
+func TestWriteFrameTimeout(t *testing.T) {
+	server, r := pipe(t)
+	shared := &deadlineConn{Conn: server}
+
+	var mut sync.Mutex
+
+	blocked := NewRequestConn(shared, &mut, 1, true)
+	other := NewRequestConn(shared, &mut, 2, true)
+
+	start := time.Now()
+
+	if err := writeFrameTimeout(FormatJSON, ActivationFinished, nil, blocked, 50*time.Millisecond); err != errWriteTimeout {
+		t.Errorf("got %v, want a timeout for an unread connection", err)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Error("write blocked after the timeout")
+	}
+
+	wg := write(t, func() {
+		if err := writeFrameTimeout(FormatJSON, QueryDone, nil, other, time.Second); err != nil {
+			t.Error(err)
+		}
+	})
+
+	first := readFrame(t, r, true)
+	second := readFrame(t, r, true)
+	wg.Wait()
+
+	if first.id != 1 || second.id != 2 {
+		t.Errorf("got ids %d and %d, want the timed out frame written first", first.id, second.id)
+	}
+
+	if shared.deadline {
+		t.Error("deadline of the shared connection was changed")
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+const (
+	ControlEnable  = "enable"
+	ControlDisable = "disable"
+	ControlDump    = "dump"
+	ControlCheck   = "check"
+)
+
+type ControlRequest struct{}
+
+func (a *ControlRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
+	req := &pb.ControlRequest{}
+
+	if err := unmarshal(format, data, req); err != nil {
+		slog.Error("controlrequesthandler", "unmarshal", err)
+
+		return
+	}
+
+	var err error
+	var out string
+
+	switch req.Command {
+	case ControlEnable:
+		err = providers.SetEnabled(req.Provider, true)
+	case ControlDisable:
+		err = providers.SetEnabled(req.Provider, false)
+	case ControlDump:
+		out, err = dump(req.Provider)
+	case ControlCheck:
+		out = check(req.Provider)
+	default:
+		err = fmt.Errorf("unknown command: %s", req.Command)
+	}
+
+	res := &pb.ControlResponse{
+		Data: out,
+	}
+
+	if err != nil {
+		slog.Error("controlrequesthandler", "command", req.Command, "provider", req.Provider, "err", err)
+		res.Error = err.Error()
+	} else {
+		slog.Info("controlrequesthandler", "command", req.Command, "provider", req.Provider)
+	}
+
+	if err := writeFrame(format, ControlResult, res, conn); err != nil {
+		slog.Error("controlrequesthandler", "write", err)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/knadh/koanf/parsers/toml/v2"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+type debugDump struct {
+	Provider   string            `json:"provider"`
+	NamePretty string            `json:"name_pretty"`
+	Enabled    bool              `json:"enabled"`
+	LogLevel   string            `json:"log_level"`
+	ConfigFile string            `json:"config_file"`
+	UserConfig map[string]any    `json:"user_config"`
+	LastQuery  *queryTiming      `json:"last_query"`
+	LastErrors []common.LogEntry `json:"last_errors"`
+	Internal   map[string]any    `json:"internal,omitempty"`
+}
+
+func dump(provider string) (string, error) {
+	p, ok := providers.Providers[provider]
+	if !ok {
+		return "", fmt.Errorf("unknown provider: %s", provider)
+	}
+
+	d := debugDump{
+		Provider:   provider,
+		NamePretty: *p.NamePretty,
+		Enabled:    providers.Enabled(provider),
+		LogLevel:   common.LogLevel(provider),
+		LastErrors: common.LastErrors(provider),
+	}
+
+	if f, err := common.ProviderConfig(provider); err == nil {
+		d.ConfigFile = f
+
+		k := koanf.New(".")
+
+		if err := k.Load(file.Provider(f), toml.Parser()); err != nil {
+			return "", err
+		}
+
+		d.UserConfig = k.Raw()
+	}
+
+	if t, ok := lastQuery(provider); ok {
+		d.LastQuery = &t
+	}
+
+	if p.Dump != nil {
+		d.Internal = p.Dump()
+	}
+
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// check returns the config warnings, limited to the given provider if not empty.
+func check(provider string) string {
+	warnings := common.ConfigWarnings()
+
+	names := []string{}
+
+	for k := range warnings {
+		if provider == "" || k == provider {
+			names = append(names, k)
+		}
+	}
+
+	if len(names) == 0 {
+		return "config ok"
+	}
+
+	slices.Sort(names)
+
+	var b strings.Builder
+
+	for _, name := range names {
+		for _, w := range warnings[name] {
+			fmt.Fprintf(&b, "%s: %s\n", name, w)
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
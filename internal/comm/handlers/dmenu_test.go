@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+func dmenuResponse(t *testing.T, format uint8, f frame) *pb.DmenuResponse {
+	t.Helper()
+
+	if f.t != DmenuResult {
+		t.Fatalf("got type %d, want %d", f.t, DmenuResult)
+	}
+
+	res := &pb.DmenuResponse{}
+
+	if err := unmarshal(format, f.payload, res); err != nil {
+		t.Fatal(err)
+	}
+
+	return res
+}
+
+func TestDmenuRoundTrip(t *testing.T) {
+	t.Cleanup(func() {
+		ConnectionClosed(10)
+	})
+
+	for _, format := range []uint8{FormatProtobuf, FormatJSON} {
+		name := fmt.Sprintf("roundtrip-%d", format)
+		provider := dmenuPrefix + name
+
+		b, err := marshal(format, &pb.DmenuRequest{
+			Name: name,
+			Items: []*pb.DmenuRequest_Item{
+				{Text: "Firefox", Subtext: "browser"},
+				{Identifier: "chromium", Text: "Chromium"},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn, r := pipe(t)
+
+		wg := write(t, func() {
+			(&DmenuRequest{}).Handle(format, 10, conn, b)
+		})
+
+		if res := dmenuResponse(t, format, readFrame(t, r, false)); !proto.Equal(res, &pb.DmenuResponse{Provider: provider}) {
+			t.Fatalf("format %d: got %v, want the provider name", format, res)
+		}
+
+		wg.Wait()
+
+		p, ok := lookupProvider(provider)
+		if !ok {
+			t.Fatalf("format %d: %s not registered", format, provider)
+		}
+
+		if got := fmt.Sprint(identifiers(p.Query(context.Background(), nil, "", true, false, format))); got != "[0 chromium]" {
+			t.Errorf("format %d: got %s, want the submitted order", format, got)
+		}
+
+		if got := fmt.Sprint(identifiers(p.Query(context.Background(), nil, "chrom", true, false, format))); got != "[chromium]" {
+			t.Errorf("format %d: got %s for chrom", format, got)
+		}
+
+		// the item is activated by another client, f.e. the launcher showing the items
+		req, err := marshal(FormatJSON, &pb.ActivateRequest{Provider: provider, Identifier: "0", Action: "select", Query: "fire", Result: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		other, or := pipe(t)
+
+		wg = write(t, func() {
+			(&ActivateRequest{}).Handle(FormatJSON, 11, other, req)
+		})
+
+		want := &pb.DmenuResponse{
+			Provider:   provider,
+			Identifier: "0",
+			Text:       "Firefox",
+			Action:     "select",
+			Query:      "fire",
+		}
+
+		if res := dmenuResponse(t, format, readFrame(t, r, false)); !proto.Equal(res, want) {
+			t.Errorf("format %d: got %v, want %v", format, res, want)
+		}
+
+		if f := readFrame(t, or, false); f.t != ActivationFinished || string(f.payload) != `{"ok":true,"status":0}` {
+			t.Errorf("format %d: got %d %s, want a successful activation", format, f.t, f.payload)
+		}
+
+		wg.Wait()
+	}
+}
+
+func TestDmenuNameInUse(t *testing.T) {
+	b, err := marshal(FormatJSON, &pb.DmenuRequest{Name: "inuse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cid := range []uint32{20, 21} {
+		t.Cleanup(func() {
+			ConnectionClosed(cid)
+		})
+
+		conn, r := pipe(t)
+
+		wg := write(t, func() {
+			(&DmenuRequest{}).Handle(FormatJSON, cid, conn, b)
+		})
+
+		res := dmenuResponse(t, FormatJSON, readFrame(t, r, false))
+		wg.Wait()
+
+		if (res.Error != "") != (cid == 21) {
+			t.Errorf("connection %d: got error %q", cid, res.Error)
+		}
+	}
+
+	ConnectionClosed(20)
+
+	if _, ok := lookupProvider(dmenuPrefix + "inuse"); ok {
+		t.Error("provider kept after its connection closed")
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// dmenuPrefix is used for the transient providers created by dmenu requests.
+const dmenuPrefix = "dmenu:"
+
+// items scoring below this don't match the query
+const dmenuMinScore = 20
+
+type DmenuRequest struct{}
+
+// dmenu is a list of items served as provider until the submitting connection closes.
+type dmenu struct {
+	cid    uint32
+	format uint8
+	conn   net.Conn
+	items  []*pb.DmenuRequest_Item
+}
+
+var (
+	dmenus   = make(map[string]*dmenu)
+	dmenuMut sync.Mutex
+)
+
+func (a *DmenuRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
+	req := &pb.DmenuRequest{}
+
+	if err := unmarshal(format, data, req); err != nil {
+		slog.Error("dmenurequesthandler", "unmarshal", err)
+
+		return
+	}
+
+	name := req.Name
+
+	if name == "" {
+		name = strconv.FormatUint(uint64(cid), 10)
+	}
+
+	provider := dmenuPrefix + name
+
+	for k, v := range req.Items {
+		if v.Identifier == "" {
+			v.Identifier = strconv.Itoa(k)
+		}
+	}
+
+	dmenuMut.Lock()
+
+	if d, ok := dmenus[provider]; ok && d.cid != cid {
+		dmenuMut.Unlock()
+
+		writeDmenuResponse(format, &pb.DmenuResponse{
+			Provider: provider,
+			Error:    fmt.Sprintf("%s is already in use", provider),
+		}, conn)
+
+		return
+	}
+
+	dmenus[provider] = &dmenu{
+		cid:    cid,
+		format: format,
+		conn:   conn,
+		items:  req.Items,
+	}
+
+	dmenuMut.Unlock()
+
+	slog.Info("dmenurequesthandler", "provider", provider, "items", len(req.Items))
+
+	writeDmenuResponse(format, &pb.DmenuResponse{Provider: provider}, conn)
+
+	ProviderUpdated <- provider
+}
+
+// ConnectionClosed removes everything bound to the connection.
+func ConnectionClosed(cid uint32) {
+	unsubscribe(cid)
+	cancelConnectionQueries(cid)
+
+	dmenuMut.Lock()
+	defer dmenuMut.Unlock()
+
+	for k, v := range dmenus {
+		if v.cid == cid {
+			delete(dmenus, k)
+		}
+	}
+}
+
+// lookupProvider returns the loaded or transient provider with the given name.
+func lookupProvider(name string) (providers.Provider, bool) {
+	if !strings.HasPrefix(name, dmenuPrefix) {
+		p, ok := providers.Providers[name]
+		return p, ok
+	}
+
+	dmenuMut.Lock()
+	d, ok := dmenus[name]
+	dmenuMut.Unlock()
+
+	if !ok {
+		return providers.Provider{}, false
+	}
+
+	return providers.Provider{
+		Name: &name,
+		Query: func(_ context.Context, _ net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+			return d.query(name, query, exact)
+		},
+	}, true
+}
+
+func (d *dmenu) query(provider, query string, exact bool) []*pb.QueryResponse_Item {
+	entries := []*pb.QueryResponse_Item{}
+
+	for k, v := range d.items {
+		e := &pb.QueryResponse_Item{
+			Identifier: v.Identifier,
+			Text:       v.Text,
+			Subtext:    v.Subtext,
+			Icon:       v.Icon,
+			Provider:   provider,
+			Actions:    []string{"select"},
+			Type:       pb.QueryResponse_REGULAR,
+		}
+
+		// keep the submitted order for the empty query
+		if query == "" {
+			e.Score = int32(len(d.items) - k)
+		} else {
+			e.Fuzzyinfo = &pb.QueryResponse_Item_FuzzyInfo{
+				Field: "text",
+			}
+
+			e.Score, e.Fuzzyinfo.Positions, e.Fuzzyinfo.Start = common.FuzzyScore(query, v.Text, exact)
+
+			if e.Score <= dmenuMinScore {
+				continue
+			}
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// activateDmenu reports the activated item to the connection that submitted the items instead of executing anything.
+func activateDmenu(format uint8, result bool, req *pb.ActivateRequest, conn net.Conn) {
+	dmenuMut.Lock()
+	d, ok := dmenus[req.Provider]
+	dmenuMut.Unlock()
+
+	if !ok {
+		writeActivationFinished(format, result, failedResult(fmt.Sprintf("unknown provider: %s", req.Provider)), conn)
+		return
+	}
+
+	res := &pb.DmenuResponse{
+		Provider:   req.Provider,
+		Identifier: req.Identifier,
+		Action:     req.Action,
+		Query:      req.Query,
+	}
+
+	for _, v := range d.items {
+		if v.Identifier == req.Identifier {
+			res.Text = v.Text
+			break
+		}
+	}
+
+	if !writeDmenuResponse(d.format, res, d.conn) {
+		writeActivationFinished(format, result, failedResult("dmenu client is gone"), conn)
+		return
+	}
+
+	writeActivationFinished(format, result, &ActivationResult{Ok: true}, conn)
+}
+
+func writeDmenuResponse(format uint8, res *pb.DmenuResponse, conn net.Conn) bool {
+	if err := writeFrame(format, DmenuResult, res, conn); err != nil {
+		slog.Error("dmenurequesthandler", "write", err)
+		return false
+	}
+
+	return true
+}
@@ -1,13 +1,11 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net"
 
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
-	"google.golang.org/protobuf/proto"
 )
 
 type MenuRequest struct{}
@@ -15,19 +13,10 @@ type MenuRequest struct{}
 func (a *MenuRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
 	req := &pb.MenuRequest{}
 
-	switch format {
-	case 0:
-		if err := proto.Unmarshal(data, req); err != nil {
-			slog.Error("menurequesthandler", "protobuf", err)
+	if err := unmarshal(format, data, req); err != nil {
+		slog.Error("menurequesthandler", "unmarshal", err)
 
-			return
-		}
-	case 1:
-		if err := json.Unmarshal(data, req); err != nil {
-			slog.Error("menurequesthandler", "protobuf", err)
-
-			return
-		}
+		return
 	}
 
 	ProviderUpdated <- fmt.Sprintf("%s:%s", "menus", req.Menu)
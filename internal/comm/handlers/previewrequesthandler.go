@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/internal/util"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+type PreviewRequest struct{}
+
+func (a *PreviewRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
+	req := &pb.PreviewRequest{}
+
+	if err := unmarshal(format, data, req); err != nil {
+		slog.Error("previewrequesthandler", "unmarshal", err)
+
+		return
+	}
+
+	provider, _, _ := strings.Cut(req.Provider, ":")
+
+	res := &pb.PreviewResponse{
+		Provider:   req.Provider,
+		Identifier: req.Identifier,
+	}
+
+	if p, ok := providers.Providers[provider]; ok && p.Preview != nil && providers.Enabled(provider) {
+		res.Preview, res.PreviewType = p.Preview(req.Identifier)
+	}
+
+	if res.PreviewType == util.PreviewTypeCommand {
+		streamPreview(format, conn, res)
+		return
+	}
+
+	if err := writeFrame(format, PreviewResult, res, conn); err != nil {
+		slog.Error("previewrequesthandler", "write", err, "provider", req.Provider)
+	}
+}
+
+// streamPreview runs a command preview and sends its output as partial text
+// previews, followed by a final empty one.
+func streamPreview(format uint8, conn net.Conn, res *pb.PreviewResponse) {
+	command := res.Preview
+
+	res.Preview = ""
+	res.PreviewType = util.PreviewTypeText
+	res.Partial = true
+
+	err := common.RunPreview(context.Background(), command, func(chunk string) error {
+		res.Preview = chunk
+		return writeFrame(format, PreviewResult, res, conn)
+	})
+	if err != nil {
+		slog.Error("previewrequesthandler", "command", err, "provider", res.Provider)
+	}
+
+	res.Preview = ""
+	res.Partial = false
+
+	if err := writeFrame(format, PreviewResult, res, conn); err != nil {
+		slog.Error("previewrequesthandler", "write", err, "provider", res.Provider)
+	}
+}
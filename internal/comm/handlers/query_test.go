@@ -0,0 +1,496 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// loadConfig loads elephant.toml with the given content.
+func loadConfig(t *testing.T, content string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "elephant"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "elephant", "elephant.toml"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	common.LoadGlobalConfig()
+}
+
+// addProvider registers a provider answering queries with query.
+func addProvider(t *testing.T, name string, query func(ctx context.Context, query string) []*pb.QueryResponse_Item) {
+	t.Helper()
+
+	if providers.Providers == nil {
+		providers.Providers = make(map[string]providers.Provider)
+	}
+
+	n := name
+
+	providers.Providers[name] = providers.Provider{
+		Name: &n,
+		Query: func(ctx context.Context, _ net.Conn, q string, _ bool, _ bool, _ uint8) []*pb.QueryResponse_Item {
+			return query(ctx, q)
+		},
+	}
+
+	t.Cleanup(func() {
+		delete(providers.Providers, name)
+	})
+}
+
+// items returns n items of the provider, scored descending from score.
+func items(provider string, n int, score int32) []*pb.QueryResponse_Item {
+	res := []*pb.QueryResponse_Item{}
+
+	for i := range n {
+		res = append(res, &pb.QueryResponse_Item{
+			Identifier: fmt.Sprintf("%s-%d", provider, i),
+			Text:       fmt.Sprintf("%s %d", provider, i),
+			Provider:   provider,
+			Score:      score - int32(i),
+		})
+	}
+
+	return res
+}
+
+type queryResult struct {
+	items    []*pb.QueryResponse_Item
+	timedout []string
+}
+
+// runQuery sends the request to the query handler and collects the answer until it's done.
+func runQuery(t *testing.T, req *pb.QueryRequest) queryResult {
+	t.Helper()
+
+	b, err := marshal(FormatJSON, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, r := pipe(t)
+
+	go (&QueryRequest{}).Handle(FormatJSON, 1, conn, b)
+
+	res := queryResult{}
+
+	for {
+		f := readFrame(t, r, false)
+
+		switch f.t {
+		case QueryDone:
+			return res
+		case QueryNoResults:
+		case QueryItem, QueryTimedOut:
+			resp := &pb.QueryResponse{}
+
+			if err := unmarshal(FormatJSON, f.payload, resp); err != nil {
+				t.Fatal(err)
+			}
+
+			if f.t == QueryTimedOut {
+				res.timedout = append(res.timedout, resp.Timedout...)
+			} else {
+				res.items = append(res.items, resp.Item)
+			}
+		default:
+			t.Fatalf("unexpected response type %d", f.t)
+		}
+	}
+}
+
+func identifiers(items []*pb.QueryResponse_Item) []string {
+	res := []string{}
+
+	for _, v := range items {
+		res = append(res, v.Identifier)
+	}
+
+	return res
+}
+
+func TestStreamSharedMaxResults(t *testing.T) {
+	loadConfig(t, "")
+
+	for _, p := range []string{"a", "b", "c"} {
+		addProvider(t, p, func(_ context.Context, _ string) []*pb.QueryResponse_Item {
+			return items(p, 5, 100)
+		})
+	}
+
+	res := runQuery(t, &pb.QueryRequest{
+		Providers:  []string{"a", "b", "c"},
+		Query:      "x",
+		Maxresults: 4,
+		Stream:     true,
+	})
+
+	if len(res.items) != 4 {
+		t.Errorf("got %d items, want 4: %v", len(res.items), identifiers(res.items))
+	}
+}
+
+func TestStreamDedup(t *testing.T) {
+	loadConfig(t, "")
+
+	for _, p := range []string{"a", "b"} {
+		addProvider(t, p, func(_ context.Context, _ string) []*pb.QueryResponse_Item {
+			res := items(p, 3, 100)
+			res[0].DedupKey = "shared"
+
+			return res
+		})
+	}
+
+	res := runQuery(t, &pb.QueryRequest{
+		Query:      "x",
+		Maxresults: 10,
+		Stream:     true,
+		Combined:   true,
+	})
+
+	shared := 0
+
+	for _, v := range res.items {
+		if v.DedupKey == "shared" {
+			shared++
+		}
+	}
+
+	if shared != 1 || len(res.items) != 5 {
+		t.Errorf("got %v, want 5 items with one of the shared key", identifiers(res.items))
+	}
+}
+
+func TestStreamEmptyCombinedInterleaves(t *testing.T) {
+	loadConfig(t, "")
+
+	addProvider(t, "a", func(_ context.Context, _ string) []*pb.QueryResponse_Item {
+		return items("a", 2, 1000)
+	})
+
+	addProvider(t, "b", func(_ context.Context, _ string) []*pb.QueryResponse_Item {
+		return items("b", 2, 10)
+	})
+
+	res := runQuery(t, &pb.QueryRequest{
+		Maxresults: 10,
+		Stream:     true,
+		Combined:   true,
+	})
+
+	got := fmt.Sprint(identifiers(res.items))
+	want := fmt.Sprint([]string{"a-0", "b-0", "a-1", "b-1"})
+
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// slow returns a provider answering after delay, or when the query is cancelled.
+func slow(name string, delay time.Duration, cancelled chan<- string) func(ctx context.Context, query string) []*pb.QueryResponse_Item {
+	return func(ctx context.Context, _ string) []*pb.QueryResponse_Item {
+		select {
+		case <-time.After(delay):
+			return items(name, 1, 100)
+		case <-ctx.Done():
+			if cancelled != nil {
+				cancelled <- name
+			}
+
+			return nil
+		}
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	loadConfig(t, "query_timeout = 50")
+
+	cancelled := make(chan string, 1)
+
+	addProvider(t, "fast", slow("fast", 0, nil))
+	addProvider(t, "slow", slow("slow", 5*time.Second, cancelled))
+
+	start := time.Now()
+
+	res := runQuery(t, &pb.QueryRequest{
+		Providers:  []string{"fast", "slow"},
+		Query:      "x",
+		Maxresults: 10,
+	})
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("query took %s, slow provider wasn't cut off", elapsed)
+	}
+
+	if got := fmt.Sprint(identifiers(res.items)); got != "[fast-0]" {
+		t.Errorf("got items %s, want the fast provider's", got)
+	}
+
+	if got := fmt.Sprint(res.timedout); got != "[slow]" {
+		t.Errorf("got timed out %s, want [slow]", got)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("context of the timed out provider wasn't cancelled")
+	}
+}
+
+func TestProviderQueryTimeout(t *testing.T) {
+	loadConfig(t, "query_timeout = 5000\n[provider_query_timeouts]\nslow = 50")
+
+	addProvider(t, "slow", slow("slow", 5*time.Second, nil))
+	addProvider(t, "patient", slow("patient", 200*time.Millisecond, nil))
+
+	res := runQuery(t, &pb.QueryRequest{
+		Providers:  []string{"slow", "patient"},
+		Query:      "x",
+		Maxresults: 10,
+	})
+
+	if got := fmt.Sprint(identifiers(res.items)); got != "[patient-0]" {
+		t.Errorf("got items %s, want [patient-0]", got)
+	}
+
+	if got := fmt.Sprint(res.timedout); got != "[slow]" {
+		t.Errorf("got timed out %s, want [slow]", got)
+	}
+}
+
+func TestQueryWithoutTimeout(t *testing.T) {
+	loadConfig(t, "")
+
+	addProvider(t, "slow", slow("slow", 200*time.Millisecond, nil))
+
+	res := runQuery(t, &pb.QueryRequest{
+		Providers:  []string{"slow"},
+		Query:      "x",
+		Maxresults: 10,
+	})
+
+	if len(res.items) != 1 || len(res.timedout) != 0 {
+		t.Errorf("got items %v and timed out %v, want the slow item", identifiers(res.items), res.timedout)
+	}
+}
+
+// blocking registers a provider answering once release is closed. The context
+// of every query is sent to started.
+func blocking(t *testing.T, name string, release <-chan struct{}) <-chan context.Context {
+	t.Helper()
+
+	started := make(chan context.Context, 10)
+
+	addProvider(t, name, func(ctx context.Context, _ string) []*pb.QueryResponse_Item {
+		started <- ctx
+
+		select {
+		case <-release:
+			return items(name, 1, 100)
+		case <-ctx.Done():
+			return nil
+		}
+	})
+
+	return started
+}
+
+// startQuery runs a multiplexed query in the background.
+func startQuery(t *testing.T, wg *sync.WaitGroup, cid, rid uint32, conn net.Conn, mut *sync.Mutex, provider string) {
+	t.Helper()
+
+	b, err := marshal(FormatJSON, &pb.QueryRequest{Providers: []string{provider}, Query: "x", Maxresults: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Go(func() {
+		(&QueryRequest{}).Handle(FormatJSON, cid, NewRequestConn(conn, mut, rid, true), b)
+	})
+}
+
+// drain reads all frames, as writes to a pipe block until read.
+func drain(r io.Reader) {
+	go io.Copy(io.Discard, r)
+}
+
+func connectionQueries(cid uint32) int {
+	queryMutex.Lock()
+	defer queryMutex.Unlock()
+
+	n := 0
+
+	for k := range queries {
+		if uint32(k>>32) == cid {
+			n++
+		}
+	}
+
+	return n
+}
+
+func cancelled(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+func TestQueryInterleavedRequestIDs(t *testing.T) {
+	loadConfig(t, "")
+
+	release := make(chan struct{})
+	started := blocking(t, "block", release)
+
+	conn, r := pipe(t)
+	drain(r)
+
+	var mut sync.Mutex
+	var wg sync.WaitGroup
+
+	startQuery(t, &wg, 7, 1, conn, &mut, "block")
+	first := <-started
+
+	startQuery(t, &wg, 7, 2, conn, &mut, "block")
+	second := <-started
+
+	if first.Err() != nil {
+		t.Error("query with another request id cancelled the first one")
+	}
+
+	// replaces the query with the same request id
+	startQuery(t, &wg, 7, 1, conn, &mut, "block")
+	third := <-started
+
+	if !cancelled(first) {
+		t.Error("query with the same request id didn't cancel the first one")
+	}
+
+	if second.Err() != nil || third.Err() != nil {
+		t.Error("running queries were cancelled")
+	}
+
+	if n := connectionQueries(7); n != 2 {
+		t.Errorf("got %d running queries, want 2", n)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if n := connectionQueries(7); n != 0 {
+		t.Errorf("got %d queries left after all finished, want 0", n)
+	}
+}
+
+func TestConnectionClosedCancelsQueries(t *testing.T) {
+	loadConfig(t, "")
+
+	release := make(chan struct{})
+	closing := blocking(t, "closing", release)
+	other := blocking(t, "other", release)
+
+	conn, r := pipe(t)
+	drain(r)
+
+	var mut sync.Mutex
+	var wg sync.WaitGroup
+
+	startQuery(t, &wg, 8, 1, conn, &mut, "closing")
+	startQuery(t, &wg, 8, 2, conn, &mut, "closing")
+	startQuery(t, &wg, 9, 1, conn, &mut, "other")
+
+	closed := []context.Context{<-closing, <-closing}
+	open := <-other
+
+	ConnectionClosed(8)
+
+	if n := connectionQueries(8); n != 0 {
+		t.Errorf("got %d queries of the closed connection, want 0", n)
+	}
+
+	if n := connectionQueries(9); n != 1 {
+		t.Errorf("got %d queries of another connection, want 1", n)
+	}
+
+	for _, v := range closed {
+		if !cancelled(v) {
+			t.Error("query of the closed connection wasn't cancelled")
+		}
+	}
+
+	if open.Err() != nil {
+		t.Error("query of another connection was cancelled")
+	}
+
+	close(release)
+	wg.Wait()
+}
+
+func TestRouteCombinedDefaults(t *testing.T) {
+	loadConfig(t, "")
+
+	for _, p := range []string{"calc", "files", "symbols"} {
+		addProvider(t, p, func(_ context.Context, _ string) []*pb.QueryResponse_Item {
+			return nil
+		})
+	}
+
+	tests := []struct {
+		query     string
+		providers int
+		want      string
+	}{
+		{"=1+1", 1, "1+1"},
+		{";heart", 1, "heart"},
+		// regular expression mode, not routed to files
+		{"/fire.*fox/", 3, "/fire.*fox/"},
+		// websearch isn't loaded
+		{"!gh", 3, "!gh"},
+	}
+
+	for _, tt := range tests {
+		res, query := routeCombined(tt.query)
+
+		if len(res) != tt.providers || query != tt.want {
+			t.Errorf("%q: got %v and %q, want %d providers and %q", tt.query, res, query, tt.providers, tt.want)
+		}
+	}
+}
+
+func TestQueryPassedAsIs(t *testing.T) {
+	loadConfig(t, "")
+
+	got := make(chan string, 1)
+
+	addProvider(t, "raw", func(_ context.Context, query string) []*pb.QueryResponse_Item {
+		got <- query
+		return nil
+	})
+
+	runQuery(t, &pb.QueryRequest{Providers: []string{"raw"}, Query: "'quoted"})
+
+	if q := <-got; q != "'quoted" {
+		t.Errorf("provider got %q, want the query unchanged", q)
+	}
+}
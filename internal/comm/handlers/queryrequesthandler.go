@@ -1,13 +1,11 @@
 package handlers
 
 import (
-	"bytes"
 	"context"
-	"encoding/binary"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net"
+	"path/filepath"
 	"slices"
 	"strings"
 	"sync"
@@ -15,6 +13,7 @@ import (
 	"time"
 
 	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
 	"google.golang.org/protobuf/proto"
 )
@@ -27,14 +26,38 @@ const (
 	QueryAsyncItem     = 1
 	ActivationFinished = 2
 	ProviderState      = 3
+	ControlResult      = 4
+	QueryTimedOut      = 5
+	PreviewResult      = 6
+	AuthResult         = 7
+	DmenuResult        = 8
 )
 
+// size used to resolve icon paths
+const iconSize = 48
+
+// max amount of remembered sent items before starting over
+const maxSentItems = 1000
+
+// runningQuery is compared by pointer, so a finished query only removes itself.
+type runningQuery struct {
+	cancel context.CancelFunc
+}
+
+type sentItem struct {
+	text    string
+	subtext string
+	icon    string
+}
+
 var (
-	queries                          = make(map[uint32]context.CancelFunc)
+	queries                          = make(map[uint64]*runningQuery)
 	queryMutex                       sync.Mutex
 	MaxGlobalItemsToDisplayWebsearch = 0
 	WebsearchPrefixes                = make(map[string]string)
 	qid                              atomic.Uint32
+	sentItems                        = make(map[string]sentItem)
+	sentItemsMut                     sync.Mutex
 )
 
 type QueryRequest struct{}
@@ -42,60 +65,30 @@ type QueryRequest struct{}
 func UpdateItem(format uint8, query string, conn net.Conn, item *pb.QueryResponse_Item) {
 	req := pb.QueryResponse{
 		Query: query,
-		Item:  item,
+		Item:  resolveIcon(item),
 	}
 
-	var b []byte
-	var err error
-
-	switch format {
-	case 0:
-		b, err = proto.Marshal(&req)
-	case 1:
-		b, err = json.Marshal(&req)
-	}
-
-	if err != nil {
-		slog.Debug("async update", "marshal", err)
-		return
-	}
-
-	var buffer bytes.Buffer
-	buffer.Write([]byte{QueryAsyncItem})
-
-	lengthBuf := make([]byte, 4)
-	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
-	buffer.Write(lengthBuf)
-	buffer.Write(b)
-
-	_, err = conn.Write(buffer.Bytes())
-	if err != nil {
+	if err := writeFrame(format, QueryAsyncItem, &req, conn); err != nil {
 		slog.Debug("async update", "write", err)
 		return
 	}
 }
 
 func (h *QueryRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
-	qid.Add(1)
-	qqid := qid.Load()
+	qqid := qid.Add(1)
 
 	start := time.Now()
 
 	req := &pb.QueryRequest{}
 
-	switch format {
-	case 0:
-		if err := proto.Unmarshal(data, req); err != nil {
-			slog.Error("queryhandler", "protobuf", err)
+	if err := unmarshal(format, data, req); err != nil {
+		slog.Error("queryhandler", "unmarshal", err)
 
-			return
-		}
-	case 1:
-		if err := json.Unmarshal(data, req); err != nil {
-			slog.Error("queryhandler", "protobuf", err)
+		return
+	}
 
-			return
-		}
+	if req.Combined {
+		req.Providers, req.Query = routeCombined(req.Query)
 	}
 
 	wsprefix := ""
@@ -108,21 +101,35 @@ func (h *QueryRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 		}
 	}
 
-	queryMutex.Lock()
+	// a new query replaces the running one of the connection. multiplexed
+	// queries only replace queries with the same request id.
+	rid, _ := requestID(conn)
+	key := uint64(cid)<<32 | uint64(rid)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	if val, ok := queries[cid]; ok {
-		if val != nil {
-			val()
-		}
-		queries[cid] = cancel
-	} else {
-		queries[cid] = cancel
+	running := &runningQuery{cancel: cancel}
+
+	queryMutex.Lock()
+
+	if prev, ok := queries[key]; ok {
+		prev.cancel()
 	}
+
+	queries[key] = running
 	queryMutex.Unlock()
 
+	defer func() {
+		queryMutex.Lock()
+
+		if queries[key] == running {
+			delete(queries, key)
+		}
+
+		queryMutex.Unlock()
+	}()
+
 	isCncld := func() bool {
 		select {
 		case <-ctx.Done():
@@ -138,6 +145,15 @@ func (h *QueryRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 	wg.Add(len(req.Providers))
 
 	entries := []*pb.QueryResponse_Item{}
+	streamed := 0
+	timedout := []string{}
+
+	// shared by all providers, streamed items count against it as they are sent
+	remaining := int(req.Maxresults)
+	sentKeys := make(map[string]bool)
+
+	// interleaving needs the results of all providers
+	stream := req.Stream && !(req.Combined && req.Query == "")
 
 	for _, v := range req.Providers {
 		query := req.Query
@@ -150,8 +166,83 @@ func (h *QueryRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 
 		go func(text string, wg *sync.WaitGroup) {
 			defer wg.Done()
-			if p, ok := providers.Providers[v]; ok {
-				res := p.Query(conn, text, len(req.Providers) == 1, req.Exactsearch, format)
+			if p, ok := lookupProvider(v); ok && providers.Enabled(v) {
+				if common.GetQueryHints(v).TooShort(req.Query) {
+					return
+				}
+
+				pctx := ctx
+
+				if timeout := queryTimeout(v); timeout > 0 {
+					var pcancel context.CancelFunc
+					pctx, pcancel = context.WithTimeout(ctx, timeout)
+					defer pcancel()
+				}
+
+				done := make(chan []*pb.QueryResponse_Item, 1)
+				pstart := time.Now()
+
+				go func() {
+					done <- p.Query(pctx, conn, text, len(req.Providers) == 1, req.Exactsearch, format)
+				}()
+
+				var res []*pb.QueryResponse_Item
+
+				select {
+				case res = <-done:
+					recordQuery(v, text, len(res), time.Since(pstart))
+				case <-pctx.Done():
+					if !isCncld() {
+						slog.Info("queryrequesthandler", "timedout", v)
+						recordQuery(v, text, 0, time.Since(pstart))
+
+						mut.Lock()
+						timedout = append(timedout, v)
+						mut.Unlock()
+					}
+
+					return
+				}
+
+				merged := len(req.Providers) > 1
+
+				if merged {
+					res = weigh(v, res)
+				}
+
+				// websearch results depend on the amount of other results, so they are held back until the end.
+				if stream && v != "websearch" {
+					if !merged {
+						slices.SortFunc(res, sortEntries)
+					}
+
+					mut.Lock()
+					defer mut.Unlock()
+
+					if req.Combined {
+						res = dedupSent(res, sentKeys)
+					}
+
+					if len(res) > remaining {
+						res = res[:remaining]
+					}
+
+					remaining -= len(res)
+					streamed += len(res)
+
+					for _, e := range res {
+						if isCncld() {
+							return
+						}
+
+						if err := writeItem(format, qqid, req.Query, e, conn); err != nil {
+							slog.Error("queryrequesthandler", "write", err, "item", e.Text)
+							return
+						}
+					}
+
+					return
+				}
 
 				mut.Lock()
 				entries = append(entries, res...)
@@ -166,20 +257,44 @@ func (h *QueryRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 		return
 	}
 
-	slices.SortFunc(entries, sortEntries)
+	if len(req.Providers) > 1 {
+		slices.SortStableFunc(entries, sortMerged)
+	} else {
+		slices.SortFunc(entries, sortEntries)
+	}
 
-	if len(entries) == 0 {
-		writeStatus(QueryNoResults, conn)
-		writeStatus(QueryDone, conn)
+	if req.Combined {
+		entries = dedupSent(entries, sentKeys)
+
+		if req.Query == "" {
+			entries = interleave(entries)
+		}
+	}
+
+	if len(timedout) > 0 {
+		res := pb.QueryResponse{
+			Qid:      int32(qqid),
+			Query:    req.Query,
+			Timedout: timedout,
+		}
+
+		if err := writeFrame(format, QueryTimedOut, &res, conn); err != nil {
+			slog.Error("queryrequesthandler", "write", err)
+		}
+	}
+
+	if len(entries) == 0 && streamed == 0 {
+		writeStatus(format, QueryNoResults, conn)
+		writeStatus(format, QueryDone, conn)
 		slog.Info("providers", "p", strings.Join(req.Providers, ","), "results", len(entries), "time", time.Since(start))
 		return
 	}
 
-	if len(entries) > int(req.Maxresults) {
-		entries = entries[:req.Maxresults]
+	if len(entries) > remaining {
+		entries = entries[:remaining]
 	}
 
-	hideWebsearch := len(req.Providers) > 1 && len(entries) > MaxGlobalItemsToDisplayWebsearch
+	hideWebsearch := len(req.Providers) > 1 && len(entries)+streamed > MaxGlobalItemsToDisplayWebsearch
 
 	for _, v := range entries {
 		if isCncld() {
@@ -190,45 +305,107 @@ func (h *QueryRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 			continue
 		}
 
-		req := pb.QueryResponse{
-			Qid:   int32(qqid),
-			Query: req.Query,
-			Item:  v,
+		if err := writeItem(format, qqid, req.Query, v, conn); err != nil {
+			slog.Error("queryrequesthandler", "write", err, "item", v.Text)
+			return
 		}
+	}
 
-		var b []byte
-		var err error
+	writeStatus(format, QueryDone, conn)
 
-		switch format {
-		case 0:
-			b, err = proto.Marshal(&req)
-		case 1:
-			b, err = json.Marshal(&req)
-		}
+	slog.Info("providers", "p", strings.Join(req.Providers, ","), "results", len(entries)+streamed, "time", time.Since(start))
+}
 
-		if err != nil {
-			slog.Error("queryrequesthandler", "marshal", err)
-			continue
-		}
+// CancelQueries cancels all running queries.
+func CancelQueries() {
+	queryMutex.Lock()
+	defer queryMutex.Unlock()
 
-		var buffer bytes.Buffer
-		buffer.Write([]byte{QueryItem})
+	for _, v := range queries {
+		v.cancel()
+	}
+}
 
-		lengthBuf := make([]byte, 4)
-		binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
-		buffer.Write(lengthBuf)
-		buffer.Write(b)
+// cancelConnectionQueries cancels and removes all running queries of the connection.
+func cancelConnectionQueries(cid uint32) {
+	queryMutex.Lock()
+	defer queryMutex.Unlock()
 
-		_, err = conn.Write(buffer.Bytes())
-		if err != nil {
-			slog.Error("queryrequesthandler", "write", err, "item", v.Text)
-			return
+	for k, v := range queries {
+		if uint32(k>>32) == cid {
+			v.cancel()
+			delete(queries, k)
 		}
 	}
+}
+
+func writeItem(format uint8, qid uint32, query string, item *pb.QueryResponse_Item, conn net.Conn) error {
+	rememberItem(item)
+
+	res := pb.QueryResponse{
+		Qid:   int32(qid),
+		Query: query,
+		Item:  resolveIcon(item),
+	}
+
+	return writeFrame(format, QueryItem, &res, conn)
+}
+
+// rememberItem keeps the displayed parts of sent items, so activations can be
+// described to activation listeners.
+func rememberItem(item *pb.QueryResponse_Item) {
+	sentItemsMut.Lock()
+	defer sentItemsMut.Unlock()
+
+	if len(sentItems) >= maxSentItems {
+		clear(sentItems)
+	}
+
+	sentItems[item.Provider+"\x00"+item.Identifier] = sentItem{
+		text:    item.Text,
+		subtext: item.Subtext,
+		icon:    item.Icon,
+	}
+}
+
+func lookupItem(provider, identifier string) sentItem {
+	sentItemsMut.Lock()
+	defer sentItemsMut.Unlock()
 
-	writeStatus(QueryDone, conn)
+	return sentItems[provider+"\x00"+identifier]
+}
+
+// resolveIcon replaces themed icon names with paths if resolve_icon_paths is set.
+// Items can be cached by providers, so a copy is returned.
+func resolveIcon(item *pb.QueryResponse_Item) *pb.QueryResponse_Item {
+	cfg := common.GetElephantConfig()
+	if cfg == nil || !cfg.ResolveIconPaths || item.Icon == "" || filepath.IsAbs(item.Icon) {
+		return item
+	}
+
+	path, ok := common.LookupIcon(item.Icon, iconSize)
+	if !ok {
+		return item
+	}
+
+	res := proto.Clone(item).(*pb.QueryResponse_Item)
+	res.Icon = path
+
+	return res
+}
+
+// queryTimeout returns the timeout for the provider, 0 means no timeout.
+func queryTimeout(provider string) time.Duration {
+	cfg := common.GetElephantConfig()
+	if cfg == nil {
+		return 0
+	}
+
+	if v, ok := cfg.ProviderQueryTimeouts[provider]; ok {
+		return time.Duration(v) * time.Millisecond
+	}
 
-	slog.Info("providers", "p", strings.Join(req.Providers, ","), "results", len(entries), "time", time.Since(start))
+	return time.Duration(cfg.QueryTimeout) * time.Millisecond
 }
 
 func sortEntries(a *pb.QueryResponse_Item, b *pb.QueryResponse_Item) int {
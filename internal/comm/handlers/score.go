@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// weigh sorts the results of a provider and replaces their scores with
+// normalized and weighted ones, so they can be merged with other providers.
+// Items and the slice can be cached by providers, so copies are sorted and returned.
+func weigh(provider string, items []*pb.QueryResponse_Item) []*pb.QueryResponse_Item {
+	res := make([]*pb.QueryResponse_Item, len(items))
+
+	for i, v := range items {
+		res[i] = proto.Clone(v).(*pb.QueryResponse_Item)
+	}
+
+	slices.SortFunc(res, sortEntries)
+
+	weight := common.Weight(provider)
+
+	for _, v := range res {
+		v.Score = int32(float64(common.NormalizeScore(v.Score)) * weight)
+	}
+
+	return res
+}
+
+// sortMerged sorts weighed results. Normalizing can make scores of a provider
+// equal, so the sort has to be stable to keep the provider's order.
+func sortMerged(a *pb.QueryResponse_Item, b *pb.QueryResponse_Item) int {
+	if c := cmp.Compare(b.Score, a.Score); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.Provider, b.Provider)
+}
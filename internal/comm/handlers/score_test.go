@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// scoredItems returns items of the provider with the given scores, in that order.
+func scoredItems(provider string, scores ...int32) []*pb.QueryResponse_Item {
+	res := []*pb.QueryResponse_Item{}
+
+	for i, v := range scores {
+		res = append(res, &pb.QueryResponse_Item{
+			Identifier: fmt.Sprintf("%s-%d", provider, i),
+			Provider:   provider,
+			Score:      v,
+		})
+	}
+
+	return res
+}
+
+// merge weighs the results of both providers and merges them like the query handler does.
+func merge(first, second []*pb.QueryResponse_Item) []string {
+	entries := append(weigh(first[0].Provider, first), weigh(second[0].Provider, second)...)
+	slices.SortStableFunc(entries, sortMerged)
+
+	res := []string{}
+
+	for _, v := range entries {
+		res = append(res, v.Identifier)
+	}
+
+	return res
+}
+
+func TestMergedOrdering(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []int32
+		b    []int32
+		want []string
+	}{
+		{
+			name: "linear scores",
+			a:    []int32{200, 300},
+			b:    []int32{250},
+			want: []string{"a-1", "b-0", "a-0"},
+		},
+		// normalizing makes these equal, each provider's order has to be kept
+		{
+			name: "normalized ties",
+			a:    []int32{999_998, 1_000_000, 999_999},
+			b:    []int32{1_000_000, 999_000},
+			want: []string{"a-1", "a-2", "a-0", "b-0", "b-1"},
+		},
+		{
+			name: "compressed scores keep their order",
+			a:    []int32{10_000, 600},
+			b:    []int32{5_000},
+			want: []string{"a-0", "b-0", "a-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		// providers answer in any order
+		for _, got := range [][]string{
+			merge(scoredItems("a", tt.a...), scoredItems("b", tt.b...)),
+			merge(scoredItems("b", tt.b...), scoredItems("a", tt.a...)),
+		} {
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestWeighKeepsProviderItems(t *testing.T) {
+	items := scoredItems("a", 100, 300, 200)
+
+	res := weigh("a", items)
+
+	if got := []int32{items[0].Score, items[1].Score, items[2].Score}; !slices.Equal(got, []int32{100, 300, 200}) {
+		t.Errorf("provider's items were changed: %v", got)
+	}
+
+	if res[0].Identifier != "a-1" || res[2].Identifier != "a-0" {
+		t.Errorf("got %s first and %s last, want the copies sorted", res[0].Identifier, res[2].Identifier)
+	}
+}
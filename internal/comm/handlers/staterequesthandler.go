@@ -1,16 +1,13 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/binary"
-	"encoding/json"
 	"log/slog"
 	"net"
 	"strings"
 
 	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
-	"google.golang.org/protobuf/proto"
 )
 
 type StateRequest struct{}
@@ -18,19 +15,10 @@ type StateRequest struct{}
 func (a *StateRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
 	req := &pb.ProviderStateRequest{}
 
-	switch format {
-	case 0:
-		if err := proto.Unmarshal(data, req); err != nil {
-			slog.Error("staterequesthandler", "protobuf", err)
+	if err := unmarshal(format, data, req); err != nil {
+		slog.Error("staterequesthandler", "unmarshal", err)
 
-			return
-		}
-	case 1:
-		if err := json.Unmarshal(data, req); err != nil {
-			slog.Error("staterequesthandler", "protobuf", err)
-
-			return
-		}
+		return
 	}
 
 	p := req.Provider
@@ -39,37 +27,24 @@ func (a *StateRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 		p = "menus"
 	}
 
-	res := providers.Providers[p].State(req.Provider)
-	res.Provider = req.Provider
-
-	var b []byte
-	var err error
-
-	switch format {
-	case 0:
-		b, err = proto.Marshal(res)
-	case 1:
-		b, err = json.Marshal(res)
-	}
-
-	if err != nil {
-		slog.Error("staterequesthandler", "marshal", err)
+	provider, ok := providers.Providers[p]
+	if !ok || !providers.Enabled(p) {
+		writeStatus(format, StatusDone, conn)
 		return
 	}
 
-	var buffer bytes.Buffer
-	buffer.Write([]byte{3})
+	res := provider.State(req.Provider)
+	res.Provider = req.Provider
+	res.Stats = providerStats(p)
 
-	lengthBuf := make([]byte, 4)
-	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
-	buffer.Write(lengthBuf)
-	buffer.Write(b)
+	hints := common.GetQueryHints(p)
+	res.MinQueryLength = int32(hints.MinQueryLength)
+	res.DebounceMs = int32(hints.DebounceMs)
 
-	_, err = conn.Write(buffer.Bytes())
-	if err != nil {
+	if err := writeFrame(format, ProviderState, res, conn); err != nil {
 		slog.Error("staterequesthandler", "write", err, "provider", req.Provider)
 		return
 	}
 
-	writeStatus(StatusDone, conn)
+	writeStatus(format, StatusDone, conn)
 }
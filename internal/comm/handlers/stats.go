@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// amount of recent query durations used for the percentiles.
+const statsSamples = 256
+
+type queryTiming struct {
+	Query    string    `json:"query"`
+	Results  int       `json:"results"`
+	Duration string    `json:"duration"`
+	Time     time.Time `json:"time"`
+}
+
+type queryStats struct {
+	count   uint64
+	samples []time.Duration
+	next    int
+	last    queryTiming
+	lastDur time.Duration
+}
+
+var (
+	statsMut sync.Mutex
+	stats    = map[string]*queryStats{}
+)
+
+func recordQuery(provider, query string, results int, d time.Duration) {
+	statsMut.Lock()
+	defer statsMut.Unlock()
+
+	s, ok := stats[provider]
+	if !ok {
+		s = &queryStats{}
+		stats[provider] = s
+	}
+
+	s.count++
+	s.lastDur = d
+	s.last = queryTiming{
+		Query:    query,
+		Results:  results,
+		Duration: d.String(),
+		Time:     time.Now(),
+	}
+
+	if len(s.samples) < statsSamples {
+		s.samples = append(s.samples, d)
+	} else {
+		s.samples[s.next] = d
+		s.next = (s.next + 1) % statsSamples
+	}
+}
+
+func lastQuery(provider string) (queryTiming, bool) {
+	statsMut.Lock()
+	defer statsMut.Unlock()
+
+	s, ok := stats[provider]
+	if !ok {
+		return queryTiming{}, false
+	}
+
+	return s.last, true
+}
+
+func providerStats(provider string) *pb.ProviderStats {
+	statsMut.Lock()
+	defer statsMut.Unlock()
+
+	s, ok := stats[provider]
+	if !ok {
+		return &pb.ProviderStats{}
+	}
+
+	sorted := slices.Clone(s.samples)
+	slices.Sort(sorted)
+
+	return &pb.ProviderStats{
+		Queries:     s.count,
+		P50Us:       percentile(sorted, 50).Microseconds(),
+		P95Us:       percentile(sorted, 95).Microseconds(),
+		LastUs:      s.lastDur.Microseconds(),
+		LastResults: int32(s.last.Results),
+	}
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	return sorted[(len(sorted)-1)*p/100]
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+func subsOf(cid uint32) []*sub {
+	mut.Lock()
+	defer mut.Unlock()
+
+	res := []*sub{}
+
+	for _, v := range subs {
+		if v.cid == cid {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+func TestConnectionClosedUnsubscribes(t *testing.T) {
+	closed, _ := pipe(t)
+	open, _ := pipe(t)
+
+	subscribe(FormatJSON, 1, 0, "a", "", closed)
+	subscribe(FormatJSON, 1, 0, "b", "", closed)
+	subscribe(FormatJSON, 2, 0, "a", "", open)
+
+	t.Cleanup(func() {
+		ConnectionClosed(2)
+	})
+
+	removed := subsOf(1)
+
+	if len(removed) != 2 {
+		t.Fatalf("got %d subscriptions, want 2", len(removed))
+	}
+
+	ConnectionClosed(1)
+
+	if len(subsOf(1)) != 0 {
+		t.Error("subscriptions of the closed connection remain")
+	}
+
+	if len(subsOf(2)) != 1 {
+		t.Error("subscription of another connection was removed")
+	}
+
+	for _, v := range removed {
+		select {
+		case _, ok := <-v.queue:
+			if ok {
+				t.Errorf("queue of %s not closed", v.provider)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("queue of %s not closed", v.provider)
+		}
+	}
+
+	// updates for the removed subscriptions must not panic on the closed queues
+	ProviderUpdated <- "a"
+}
+
+func TestWatchStopsOnClose(t *testing.T) {
+	loadConfig(t, "")
+
+	queried := make(chan struct{}, 100)
+
+	addProvider(t, "watched", func(_ context.Context, _ string) []*pb.QueryResponse_Item {
+		queried <- struct{}{}
+		return nil
+	})
+
+	conn, _ := pipe(t)
+
+	subscribe(FormatJSON, 3, 5, "watched", "x", conn)
+
+	<-queried
+
+	ConnectionClosed(3)
+
+	// a query might have been in flight while closing
+	time.Sleep(50 * time.Millisecond)
+
+	for len(queried) > 0 {
+		<-queried
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if len(queried) != 0 {
+		t.Error("subscription still queried after the connection was closed")
+	}
+}
@@ -2,9 +2,7 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/binary"
-	"encoding/json"
+	"context"
 	"log/slog"
 	"net"
 	"slices"
@@ -15,7 +13,6 @@ import (
 
 	"github.com/abenz1267/elephant/v2/internal/providers"
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
-	"google.golang.org/protobuf/proto"
 )
 
 type SubscribeRequest struct{}
@@ -23,22 +20,13 @@ type SubscribeRequest struct{}
 func (a *SubscribeRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
 	req := &pb.SubscribeRequest{}
 
-	switch format {
-	case 0:
-		if err := proto.Unmarshal(data, req); err != nil {
-			slog.Error("activationrequesthandler", "protobuf", err)
+	if err := unmarshal(format, data, req); err != nil {
+		slog.Error("subscriberequesthandler", "unmarshal", err)
 
-			return
-		}
-	case 1:
-		if err := json.Unmarshal(data, req); err != nil {
-			slog.Error("activationrequesthandler", "protobuf", err)
-
-			return
-		}
+		return
 	}
 
-	subscribe(format, int(req.Interval), req.Provider, req.Query, conn)
+	subscribe(format, cid, int(req.Interval), req.Provider, req.Query, conn)
 }
 
 var (
@@ -53,20 +41,25 @@ const (
 	SubscriptionHealthCheck = 230
 )
 
+// pending updates per subscriber, the oldest ones are dropped if a client can't keep up
+const subscriptionQueueSize = 32
+
 type sub struct {
 	format   uint8
+	cid      uint32
 	sid      uint32
 	interval int
 	provider string
 	query    string
 	results  []*pb.QueryResponse_Item
 	conn     net.Conn
+	queue    chan string
 }
 
 func init() {
 	sid.Store(100_000_000)
 	subs = make(map[uint32]*sub)
-	ProviderUpdated = make(chan string)
+	ProviderUpdated = make(chan string, 64)
 
 	// go checkHealth()
 
@@ -83,29 +76,28 @@ func init() {
 				p = "bluetooth"
 			}
 
-			toDelete := []uint32{}
+			if strings.HasPrefix(p, dmenuPrefix) {
+				p = "dmenu"
+			}
 
-			for k, v := range subs {
-				if v.provider == p && v.interval == 0 && v.query == "" {
-					if ok := updated(v.format, v.conn, value); !ok {
-						toDelete = append(toDelete, k)
-					}
+			mut.Lock()
+
+			for _, v := range subs {
+				if v.provider == p && v.queue != nil {
+					enqueue(v.queue, value)
 				}
 			}
 
-			for _, v := range toDelete {
-				delete(subs, v)
-			}
+			mut.Unlock()
 		}
 	}()
 }
 
-func subscribe(format uint8, interval int, provider, query string, conn net.Conn) {
-	sid.Add(1)
-
+func subscribe(format uint8, cid uint32, interval int, provider, query string, conn net.Conn) {
 	sub := &sub{
 		format:   format,
-		sid:      sid.Load(),
+		cid:      cid,
+		sid:      sid.Add(1),
 		interval: interval,
 		provider: provider,
 		query:    query,
@@ -113,6 +105,11 @@ func subscribe(format uint8, interval int, provider, query string, conn net.Conn
 		results:  []*pb.QueryResponse_Item{},
 	}
 
+	if interval == 0 && query == "" {
+		sub.queue = make(chan string, subscriptionQueueSize)
+		go deliver(sub)
+	}
+
 	mut.Lock()
 	subs[sub.sid] = sub
 	mut.Unlock()
@@ -124,17 +121,75 @@ func subscribe(format uint8, interval int, provider, query string, conn net.Conn
 	slog.Info("subscription", "new", sub.provider)
 }
 
+// enqueue never blocks, if the queue is full the oldest update is dropped.
+func enqueue(queue chan string, value string) {
+	for {
+		select {
+		case queue <- value:
+			return
+		default:
+			select {
+			case <-queue:
+			default:
+			}
+		}
+	}
+}
+
+// unsubscribe removes all subscriptions of the connection and stops their delivery.
+func unsubscribe(cid uint32) {
+	mut.Lock()
+	defer mut.Unlock()
+
+	for k, v := range subs {
+		if v.cid != cid {
+			continue
+		}
+
+		delete(subs, k)
+
+		if v.queue != nil {
+			close(v.queue)
+		}
+	}
+}
+
+func subscribed(s *sub) bool {
+	mut.Lock()
+	defer mut.Unlock()
+
+	_, ok := subs[s.sid]
+
+	return ok
+}
+
+func removeSub(s *sub) {
+	mut.Lock()
+	delete(subs, s.sid)
+	mut.Unlock()
+}
+
+func deliver(s *sub) {
+	for value := range s.queue {
+		if ok := updated(s.format, s.conn, s.provider, value); !ok {
+			removeSub(s)
+
+			return
+		}
+	}
+}
+
 func watch(format uint8, s *sub, conn net.Conn) {
 	p := providers.Providers[s.provider]
 
 	for {
 		time.Sleep(time.Duration(s.interval) * time.Millisecond)
 
-		if _, ok := subs[s.sid]; !ok {
+		if !subscribed(s) {
 			return
 		}
 
-		res := p.Query(conn, s.query, true, false, format)
+		res := p.Query(context.Background(), conn, s.query, true, false, format)
 
 		slices.SortFunc(res, sortEntries)
 
@@ -143,8 +198,8 @@ func watch(format uint8, s *sub, conn net.Conn) {
 			if len(res) != len(s.results) {
 				s.results = res
 
-				if ok := updated(format, conn, ""); !ok {
-					delete(subs, s.sid)
+				if ok := updated(format, conn, s.provider, ""); !ok {
+					removeSub(s)
 				}
 
 				continue
@@ -155,8 +210,8 @@ func watch(format uint8, s *sub, conn net.Conn) {
 				if !equals(v, s.results[k]) {
 					s.results = res
 
-					if ok := updated(format, conn, ""); !ok {
-						delete(subs, s.sid)
+					if ok := updated(format, conn, s.provider, ""); !ok {
+						removeSub(s)
 					}
 
 					break
@@ -168,35 +223,13 @@ func watch(format uint8, s *sub, conn net.Conn) {
 	}
 }
 
-func updated(format uint8, conn net.Conn, value string) bool {
+func updated(format uint8, conn net.Conn, provider, value string) bool {
 	resp := pb.SubscribeResponse{
-		Value: value,
-	}
-
-	var b []byte
-	var err error
-
-	switch format {
-	case 0:
-		b, err = proto.Marshal(&resp)
-	case 1:
-		b, err = json.Marshal(&resp)
-	}
-
-	if err != nil {
-		panic(err)
+		Provider: provider,
+		Value:    value,
 	}
 
-	var buffer bytes.Buffer
-	buffer.Write([]byte{SubscriptionDataChanged})
-
-	lengthBuf := make([]byte, 4)
-	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
-	buffer.Write(lengthBuf)
-	buffer.Write(b)
-
-	_, err = conn.Write(buffer.Bytes())
-	if err != nil {
+	if err := writeFrame(format, SubscriptionDataChanged, &resp, conn); err != nil {
 		slog.Debug("subscriptionrequesthandler", "write", err, "value", value)
 		return false
 	}
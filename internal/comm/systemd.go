@@ -0,0 +1,165 @@
+package comm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/internal/comm/handlers"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// first file descriptor passed by systemd socket activation
+const listenFdsStart = 3
+
+// activated is set if the listener was inherited from systemd.
+var activated bool
+
+// activationListener returns the socket passed by systemd, if any.
+func activationListener() (*net.UnixListener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, nil
+	}
+
+	// not meant for launched programs
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	if fds > 1 {
+		slog.Warn("comm", "activation", "more than one socket passed, using the first one")
+	}
+
+	f := os.NewFile(listenFdsStart, "elephant.sock")
+	defer f.Close()
+
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, err
+	}
+
+	ul, ok := l.(*net.UnixListener)
+	if !ok {
+		l.Close()
+		return nil, errors.New("passed socket isn't a unix socket")
+	}
+
+	return ul, nil
+}
+
+// Notify sends a state like READY=1 to systemd. Does nothing if not run as a notify service.
+func Notify(state string) {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return
+	}
+
+	// abstract namespace
+	if addr[0] == '@' {
+		addr = "\x00" + addr[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		slog.Error("comm", "notify", err)
+		return
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		slog.Error("comm", "notify", err)
+	}
+}
+
+// Watchdog pings systemd in half the interval set in WATCHDOG_USEC, as long as
+// the socket still accepts and handles connections. Blocks, so it's meant to
+// run in its own goroutine.
+func Watchdog() {
+	usec, err := strconv.Atoi(os.Getenv("WATCHDOG_USEC"))
+	if err != nil || usec <= 0 {
+		return
+	}
+
+	if pid, err := strconv.Atoi(os.Getenv("WATCHDOG_PID")); err == nil && pid != os.Getpid() {
+		return
+	}
+
+	interval := time.Duration(usec) * time.Microsecond / 2
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := probe(interval); err != nil {
+			slog.Warn("comm", "watchdog", err)
+			continue
+		}
+
+		Notify("WATCHDOG=1")
+	}
+}
+
+// probe connects to the socket like a client would and authenticates, which
+// passes the accept loop, the connection handler and the response writer.
+func probe(timeout time.Duration) error {
+	conn, err := net.DialTimeout("unix", Socket, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	payload, err := json.Marshal(&pb.AuthRequest{Secret: secret})
+	if err != nil {
+		return err
+	}
+
+	req := []byte{AuthRequestPos, handlers.FormatJSONLines}
+	req = binary.BigEndian.AppendUint32(req, uint32(len(payload)))
+	req = append(req, payload...)
+
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		Data pb.AuthResponse `json:"data"`
+	}
+
+	if err := json.Unmarshal(line, &res); err != nil {
+		return err
+	}
+
+	if res.Data.Error != "" {
+		return errors.New(res.Data.Error)
+	}
+
+	return nil
+}
+
+// RemoveSocket removes the socket, unless it's owned by systemd.
+func RemoveSocket() {
+	if activated || Socket == "" {
+		return
+	}
+
+	os.Remove(Socket)
+}
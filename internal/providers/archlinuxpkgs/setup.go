@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"encoding/json"
 	"fmt"
@@ -227,7 +228,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 	}
 }
 
-func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func Query(_ context.Context, conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	cacheChan <- struct{}{}
 
 	entries := []*pb.QueryResponse_Item{}
@@ -296,7 +297,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 			}
 		}
 
-		if query == "" || e.Score > config.MinScore {
+		if query == "" || e.Score > config.MinScoreFor(query) {
 			entries = append(entries, e)
 		}
 	}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"log/slog"
@@ -39,7 +40,7 @@ var readme string
 
 type Config struct {
 	common.Config      `koanf:",squash"`
-	Location           string     `koanf:"location" desc:"location of the CSV file" default:"elephant cache dir"`
+	Location           string     `koanf:"location" desc:"location of the CSV file" default:"elephant cache dir" expand:"true"`
 	Categories         []Category `koanf:"categories" desc:"categories" default:""`
 	Browsers           []Browser  `koanf:"browsers" desc:"browsers for opening bookmarks" default:""`
 	SetBrowserOnImport bool       `koanf:"set_browser_on_import" desc:"set browser name on imported bookmarks" default:"false"`
@@ -633,7 +634,7 @@ func importBrowserBookmarks() {
 	}
 }
 
-func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func Query(_ context.Context, conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	if isGit && config.r == nil {
 		common.SetupGit(Name, config)
 		loadBookmarks()
@@ -671,7 +672,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 				_, e.Score, e.Fuzzyinfo.Positions, e.Fuzzyinfo.Start, _ = calcScore(query, b, exact)
 			}
 
-			if config.History && e.Score > config.MinScore || query == "" && config.HistoryWhenEmpty {
+			if config.History && e.Score > config.MinScoreFor(query) || query == "" && config.HistoryWhenEmpty {
 				usageScore := h.CalcUsageScore(query, e.Identifier)
 
 				if usageScore != 0 {
@@ -685,7 +686,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 				highestScore = e.Score
 			}
 
-			if query == "" || e.Score > config.MinScore {
+			if query == "" || e.Score > config.MinScoreFor(query) {
 				entries = append(entries, e)
 			}
 		}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/gob"
 	"encoding/hex"
@@ -187,7 +188,7 @@ func saveToHistory(query, result string) {
 	saveHist()
 }
 
-func Query(conn net.Conn, query string, single bool, _ bool, format uint8) []*pb.QueryResponse_Item {
+func Query(ctx context.Context, conn net.Conn, query string, single bool, _ bool, format uint8) []*pb.QueryResponse_Item {
 	start := time.Now()
 
 	entries := []*pb.QueryResponse_Item{}
@@ -246,7 +247,7 @@ func Query(conn net.Conn, query string, single bool, _ bool, format uint8) []*pb
 
 			entries = append(entries, e)
 		} else {
-			cmd := exec.Command("qalc", "-t", query)
+			cmd := exec.CommandContext(ctx, "qalc", "-t", query)
 
 			out, err := cmd.Output()
 			if err == nil {
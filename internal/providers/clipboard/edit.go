@@ -0,0 +1,150 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+const (
+	editPollInterval = 500 * time.Millisecond
+	// editors exiting faster handed the file to another process, f.e. xdg-open
+	editMinRuntime = 2 * time.Second
+	// max time to wait for changes, if the editor can't be waited on
+	editTimeout = time.Hour
+)
+
+// editText opens the text in an editor. Saved changes replace the item.
+func editText(identifier, content string, conn net.Conn) {
+	tmpFile, err := os.CreateTemp("", "*.txt")
+	if err != nil {
+		slog.Error(Name, "edit", err)
+		return
+	}
+
+	tmpFile.Write([]byte(content))
+	tmpFile.Close()
+
+	exited := make(chan time.Time, 1)
+
+	// launch prefixes return right away, the editor itself has to be waited on
+	opts := common.RunOpts{
+		NoPrefix: true,
+		OnExit: func(_ error) {
+			exited <- time.Now()
+		},
+	}
+
+	switch editor := getConfig().TextEditorCmd; {
+	case editor != "":
+		opts.Command = strings.ReplaceAll(editor, "%FILE%", tmpFile.Name())
+	case os.Getenv("EDITOR") != "":
+		opts.Command = fmt.Sprintf("%s %s", os.Getenv("EDITOR"), tmpFile.Name())
+		opts.Terminal = true
+	default:
+		opts.Command = fmt.Sprintf("xdg-open file://%s", tmpFile.Name())
+		opts.Terminal = common.ForceTerminalForFile(tmpFile.Name())
+	}
+
+	start := time.Now()
+
+	if _, err := common.RunDetached(opts); err != nil {
+		slog.Error(Name, "openedit", err)
+		common.ReportActivationError(conn, fmt.Errorf("opening editor failed: %w", err))
+		os.Remove(tmpFile.Name())
+		return
+	}
+
+	go watchEdit(identifier, content, tmpFile.Name(), start, exited)
+}
+
+// watchEdit applies every save of the file until the editor exited. If the
+// editor exited right away, it's watched until the first save or editTimeout.
+// The file is removed afterwards.
+func watchEdit(identifier, content, file string, start time.Time, exited <-chan time.Time) {
+	defer os.Remove(file)
+
+	ticker := time.NewTicker(editPollInterval)
+	defer ticker.Stop()
+
+	timeout := time.After(editTimeout)
+	finished := false
+
+	for {
+		select {
+		case <-ticker.C:
+		case t := <-exited:
+			exited = nil
+
+			if t.Sub(start) >= editMinRuntime {
+				finished = true
+			} else {
+				slog.Info(Name, "edit", "editor returned right away, waiting for changes", "file", file)
+			}
+		case <-timeout:
+			finished = true
+		}
+
+		b, err := os.ReadFile(file)
+		if err != nil {
+			slog.Error(Name, "edit", err)
+			return
+		}
+
+		if string(b) != content {
+			content = string(b)
+			identifier = applyEdit(identifier, b)
+
+			// the editor can't be waited on, so the first save ends the edit
+			if exited == nil {
+				finished = true
+			}
+		}
+
+		if finished {
+			return
+		}
+	}
+}
+
+// applyEdit replaces the content of the edited item, moves it to the top and
+// copies it. Returns the new identifier of the item.
+func applyEdit(identifier string, b []byte) string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	item, ok := clipboardhistory[identifier]
+	if !ok || item.Content == string(b) {
+		return identifier
+	}
+
+	md5 := md5.Sum(b)
+	md5str := hex.EncodeToString(md5[:])
+
+	delete(clipboardhistory, identifier)
+
+	if existing, ok := clipboardhistory[md5str]; ok {
+		existing.Pinned = existing.Pinned || item.Pinned
+		item = existing
+	} else {
+		item.Content = string(b)
+		clipboardhistory[md5str] = item
+	}
+
+	item.Time = time.Now()
+
+	if err := copyContent(b, "text/plain"); err != nil {
+		slog.Error(Name, "edit", err)
+	}
+
+	saveToFile()
+
+	return md5str
+}
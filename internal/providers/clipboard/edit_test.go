@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setupEdit sets up a history with one item and a config not touching the real clipboard.
+func setupEdit(t *testing.T, editor string) {
+	t.Helper()
+
+	c := defaultConfig()
+	c.Persist = false
+	c.Command = "cat > /dev/null"
+	c.TextEditorCmd = editor
+
+	prevConfig, prevHistory := config, clipboardhistory
+
+	config = c
+	clipboardhistory = map[string]*Item{
+		"old": {Content: "old", Time: time.Now().Add(-time.Hour)},
+	}
+
+	t.Cleanup(func() {
+		config, clipboardhistory = prevConfig, prevHistory
+	})
+}
+
+func contents() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	res := []string{}
+
+	for _, v := range clipboardhistory {
+		res = append(res, v.Content)
+	}
+
+	return res
+}
+
+func editFile(t *testing.T) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "edit.txt")
+
+	if err := os.WriteFile(file, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+// runWatch runs watchEdit in the background and returns a channel closed when it returned.
+func runWatch(file string, start time.Time, exited chan time.Time) chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		watchEdit("old", "old", file, start, exited)
+		close(done)
+	}()
+
+	return done
+}
+
+func waitDone(t *testing.T, done chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("edit wasn't finished")
+	}
+}
+
+func TestWatchEditWaitsForEditor(t *testing.T) {
+	setupEdit(t, "")
+
+	file := editFile(t)
+	exited := make(chan time.Time, 1)
+	done := runWatch(file, time.Now().Add(-time.Minute), exited)
+
+	os.WriteFile(file, []byte("first"), 0o600)
+	time.Sleep(2 * editPollInterval)
+
+	if got := contents(); len(got) != 1 || got[0] != "first" {
+		t.Errorf("got %v after saving, want [first]", got)
+	}
+
+	os.WriteFile(file, []byte("second"), 0o600)
+	exited <- time.Now()
+
+	waitDone(t, done)
+
+	if got := contents(); len(got) != 1 || got[0] != "second" {
+		t.Errorf("got %v after exiting, want [second]", got)
+	}
+
+	if _, err := os.Stat(file); err == nil {
+		t.Error("file wasn't removed")
+	}
+}
+
+func TestWatchEditEditorReturningRightAway(t *testing.T) {
+	setupEdit(t, "")
+
+	file := editFile(t)
+	exited := make(chan time.Time, 1)
+	done := runWatch(file, time.Now(), exited)
+
+	exited <- time.Now()
+	time.Sleep(2 * editPollInterval)
+
+	select {
+	case <-done:
+		t.Fatal("edit finished before the file was saved")
+	default:
+	}
+
+	if _, err := os.Stat(file); err != nil {
+		t.Fatal("file was removed before it was saved")
+	}
+
+	os.WriteFile(file, []byte("edited"), 0o600)
+
+	waitDone(t, done)
+
+	if got := contents(); len(got) != 1 || got[0] != "edited" {
+		t.Errorf("got %v, want [edited]", got)
+	}
+}
+
+func TestEditTextForkingEditor(t *testing.T) {
+	// forks into the background like xdg-open or gui editors do
+	setupEdit(t, "sh -c '(sleep 0.5; echo -n edited > %FILE%) &'")
+
+	editText("old", "old", nil)
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		if got := contents(); len(got) == 1 && got[0] == "edited" {
+			return
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Errorf("got %v, edit of a forking editor was lost", contents())
+}
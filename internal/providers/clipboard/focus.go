@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"os/exec"
+)
+
+// focusedApp returns the app id of the focused window, if the compositor exposes it.
+func focusedApp() string {
+	var window struct {
+		Class string `json:"class"`
+		AppID string `json:"app_id"`
+	}
+
+	var cmd *exec.Cmd
+
+	switch {
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		cmd = exec.Command("hyprctl", "activewindow", "-j")
+	case os.Getenv("NIRI_SOCKET") != "":
+		cmd = exec.Command("niri", "msg", "-j", "focused-window")
+	default:
+		return ""
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		slog.Error(Name, "focusedapp", err)
+		return ""
+	}
+
+	if err := json.Unmarshal(out, &window); err != nil {
+		slog.Error(Name, "focusedapp", err)
+		return ""
+	}
+
+	// hyprland calls the app id class
+	if window.Class != "" {
+		return window.Class
+	}
+
+	return window.AppID
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// linux input event codes used for ydotool
+var keyCodes = map[string]int{
+	"ctrl": 29, "shift": 42, "alt": 56, "super": 125, "insert": 110,
+	"q": 16, "w": 17, "e": 18, "r": 19, "t": 20, "y": 21, "u": 22, "i": 23, "o": 24, "p": 25,
+	"a": 30, "s": 31, "d": 32, "f": 33, "g": 34, "h": 35, "j": 36, "k": 37, "l": 38,
+	"z": 44, "x": 45, "c": 46, "v": 47, "b": 48, "n": 49, "m": 50,
+}
+
+// modifier names as understood by wtype
+var wtypeModifiers = map[string]string{
+	"ctrl": "ctrl", "shift": "shift", "alt": "alt", "super": "logo",
+}
+
+// paste sends the configured keys to the focused window after paste_delay.
+func paste() error {
+	cfg := getConfig()
+	tool := cfg.PasteTool
+
+	if tool == "" {
+		for _, v := range []string{"wtype", "ydotool"} {
+			if _, err := exec.LookPath(v); err == nil && (v != "wtype" || os.Getenv("WAYLAND_DISPLAY") != "") {
+				tool = v
+				break
+			}
+		}
+	}
+
+	if tool == "" {
+		return errors.New("neither wtype nor ydotool found")
+	}
+
+	args, err := pasteArgs(tool, cfg.PasteKeys)
+	if err != nil {
+		return err
+	}
+
+	if _, err := exec.LookPath(tool); err != nil {
+		return err
+	}
+
+	go func() {
+		time.Sleep(time.Duration(cfg.PasteDelay) * time.Millisecond)
+
+		if out, err := exec.Command(tool, args...).CombinedOutput(); err != nil {
+			slog.Error(Name, "paste", err, "out", string(out))
+		}
+	}()
+
+	return nil
+}
+
+// pasteArgs translates keys like "ctrl+shift+v" to the arguments of the tool.
+func pasteArgs(tool, keys string) ([]string, error) {
+	parts := strings.Split(strings.ToLower(keys), "+")
+	mods, key := parts[:len(parts)-1], parts[len(parts)-1]
+
+	switch tool {
+	case "wtype":
+		args := []string{}
+
+		for _, v := range mods {
+			m, ok := wtypeModifiers[v]
+			if !ok {
+				return nil, fmt.Errorf("unknown modifier: %s", v)
+			}
+
+			args = append(args, "-M", m)
+		}
+
+		args = append(args, "-k", key)
+
+		for _, v := range slices.Backward(mods) {
+			args = append(args, "-m", wtypeModifiers[v])
+		}
+
+		return args, nil
+	case "ydotool":
+		args := []string{"key"}
+
+		for _, v := range parts {
+			code, ok := keyCodes[v]
+			if !ok {
+				return nil, fmt.Errorf("unknown key: %s", v)
+			}
+
+			args = append(args, fmt.Sprintf("%d:1", code))
+		}
+
+		for _, v := range slices.Backward(parts) {
+			args = append(args, fmt.Sprintf("%d:0", keyCodes[v]))
+		}
+
+		return args, nil
+	}
+
+	return nil, fmt.Errorf("unknown paste_tool: %s", tool)
+}
@@ -4,24 +4,27 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/md5"
 	_ "embed"
 	"encoding/gob"
 	"encoding/hex"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
 	"log/slog"
+	"mime"
 	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 	"unicode/utf8"
 
@@ -36,6 +39,7 @@ var (
 	file             = common.CacheFile("clipboard.gob")
 	imgTypes         = make(map[string]string)
 	config           *Config
+	configMu         sync.RWMutex
 	clipboardhistory = make(map[string]*Item)
 	mu               sync.Mutex
 	currentMode      = Combined
@@ -55,42 +59,70 @@ var symbolsdata string
 var (
 	paused       bool
 	saveFileChan = make(chan struct{})
+	watchCmd     *exec.Cmd
 )
 
 const StateEditable = "editable"
 
+// version of the persisted history, bump on incompatible changes
+const historyVersion = 1
+
 type Item struct {
 	Content string
 	Img     string
+	Thumb   string
 	Time    time.Time
 	State   string
+	Pinned  bool
+}
+
+// history is the persisted format, older releases stored the map directly.
+type history struct {
+	Version int
+	Items   map[string]*Item
 }
 
 type Config struct {
 	common.Config  `koanf:",squash"`
-	MaxItems       int    `koanf:"max_items" desc:"max amount of clipboard history items" default:"100"`
-	ImageEditorCmd string `koanf:"image_editor_cmd" desc:"editor to use for images. use '%FILE%' as placeholder for file path." default:""`
-	TextEditorCmd  string `koanf:"text_editor_cmd" desc:"editor to use for text, otherwise default for mimetype. use '%FILE%' as placeholder for file path." default:""`
-	Command        string `koanf:"command" desc:"default command to be executed" default:"wl-copy"`
-	IgnoreSymbols  bool   `koanf:"ignore_symbols" desc:"ignores symbols/unicode" default:"true"`
-	AutoCleanup    int    `koanf:"auto_cleanup" desc:"will automatically cleanup entries entries older than X minutes" default:"0"`
+	MaxItems       int      `koanf:"max_items" desc:"max amount of clipboard history items, pinned items don't count" default:"100"`
+	MaxImageSize   int      `koanf:"max_image_size" desc:"images larger than this are not stored, in MiB. 0 disables the limit" default:"20"`
+	Persist        bool     `koanf:"persist" desc:"keep the history across restarts" default:"true"`
+	LazyPreviews   bool     `koanf:"lazy_previews" desc:"send previews only on request, for frontends supporting it" default:"false"`
+	ImageEditorCmd string   `koanf:"image_editor_cmd" desc:"editor to use for images. use '%FILE%' as placeholder for file path." default:""`
+	TextEditorCmd  string   `koanf:"text_editor_cmd" desc:"editor to use for text, otherwise $EDITOR in a terminal or the default for the mimetype. use '%FILE%' as placeholder for file path." default:""`
+	Command        string   `koanf:"command" desc:"command used to copy, uses the global clipboard_command or detects wl-copy, xclip or xsel if empty" default:""`
+	IgnoreSymbols  bool     `koanf:"ignore_symbols" desc:"ignores symbols/unicode" default:"true"`
+	AutoCleanup    int      `koanf:"auto_cleanup" desc:"will automatically cleanup entries entries older than X minutes" default:"0"`
+	IgnorePatterns []string `koanf:"ignore_patterns" desc:"text matching one of these regular expressions isn't stored" default:"<empty>"`
+	IgnoreApps     []string `koanf:"ignore_apps" desc:"content copied while one of these apps is focused isn't stored. needs hyprland or niri" default:"<empty>"`
+	PasteTool      string   `koanf:"paste_tool" desc:"tool used by the paste action, wtype or ydotool. detected if empty" default:""`
+	PasteKeys      string   `koanf:"paste_keys" desc:"keys sent by the paste action, f.e. ctrl+shift+v for terminals" default:"ctrl+v"`
+	PasteDelay     int      `koanf:"paste_delay" desc:"ms to wait before pasting, so the previous window gets focused again" default:"300"`
 }
 
-func Setup() {
-	start := time.Now()
-
-	config = &Config{
+func defaultConfig() *Config {
+	return &Config{
 		Config: common.Config{
 			Icon:     "user-bookmarks",
 			MinScore: 30,
 		},
 		MaxItems:       100,
+		MaxImageSize:   20,
+		Persist:        true,
 		ImageEditorCmd: "",
 		TextEditorCmd:  "",
-		Command:        "wl-copy",
+		Command:        "",
 		IgnoreSymbols:  true,
 		AutoCleanup:    0,
+		PasteKeys:      "ctrl+v",
+		PasteDelay:     300,
 	}
+}
+
+func Setup() {
+	start := time.Now()
+
+	config = defaultConfig()
 
 	common.LoadConfig(Name, config)
 
@@ -98,6 +130,8 @@ func Setup() {
 		NamePretty = config.NamePretty
 	}
 
+	ignorePatterns = compilePatterns(config)
+
 	imgTypes["image/png"] = "png"
 	imgTypes["image/jpg"] = "jpg"
 	imgTypes["image/jpeg"] = "jpeg"
@@ -108,7 +142,13 @@ func Setup() {
 		hasLocalsend = true
 	}
 
-	loadFromFile()
+	if config.Persist {
+		loadFromFile()
+	} else {
+		// leftovers from a previous run with persistence
+		os.Remove(file)
+		cleanupImages()
+	}
 
 	go handleChange()
 	go handleSaveToFile()
@@ -124,6 +164,76 @@ func Setup() {
 	slog.Info(Name, "history", len(clipboardhistory), "time", time.Since(start))
 }
 
+// Reload applies a changed config. Changing ignore_symbols or auto_cleanup requires a restart.
+func Reload() {
+	c := defaultConfig()
+
+	if err := common.ReloadConfig(Name, c); err != nil {
+		slog.Error(Name, "reload", err)
+		return
+	}
+
+	old := getConfig()
+
+	c.IgnoreSymbols = old.IgnoreSymbols
+	c.AutoCleanup = old.AutoCleanup
+
+	patterns := compilePatterns(c)
+
+	mu.Lock()
+
+	configMu.Lock()
+	config = c
+	ignorePatterns = patterns
+	configMu.Unlock()
+
+	if c.MaxItems < old.MaxItems {
+		saveToFile()
+	}
+
+	mu.Unlock()
+
+	if c.NamePretty != "" {
+		NamePretty = c.NamePretty
+	}
+}
+
+// getConfig returns the current config. Reload replaces it, so it's not changed afterwards.
+func getConfig() *Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
+	return config
+}
+
+func Dump() map[string]any {
+	mu.Lock()
+	defer mu.Unlock()
+
+	images := 0
+	pinned := 0
+
+	for _, v := range clipboardhistory {
+		if v.Img != "" {
+			images++
+		}
+
+		if v.Pinned {
+			pinned++
+		}
+	}
+
+	return map[string]any{
+		"items":  len(clipboardhistory),
+		"images": images,
+		"pinned": pinned,
+		"paused": paused,
+		"mode":   currentMode,
+		"file":   file,
+		"config": getConfig(),
+	}
+}
+
 func Available() bool {
 	p, err := exec.LookPath("wl-paste")
 	if p == "" || err != nil {
@@ -141,15 +251,17 @@ func Available() bool {
 }
 
 func cleanup() {
+	minutes := getConfig().AutoCleanup
+
 	for {
-		time.Sleep(time.Duration(config.AutoCleanup) * time.Minute)
+		time.Sleep(time.Duration(minutes) * time.Minute)
 
 		i := 0
 
 		now := time.Now()
 
 		for k, v := range clipboardhistory {
-			if now.Sub(v.Time).Minutes() >= float64(config.AutoCleanup) {
+			if !v.Pinned && now.Sub(v.Time).Minutes() >= float64(minutes) {
 				delete(clipboardhistory, k)
 				i++
 			}
@@ -237,24 +349,61 @@ func setupUnicodeSymbols() {
 }
 
 func loadFromFile() {
-	if common.FileExists(file) {
-		f, err := os.ReadFile(file)
-		if err != nil {
-			slog.Error("history", "load", err)
-		} else {
-			decoder := gob.NewDecoder(bytes.NewReader(f))
+	if !common.FileExists(file) {
+		return
+	}
 
-			err = decoder.Decode(&clipboardhistory)
-			if err != nil {
-				slog.Error("history", "decoding", err)
-			}
+	f, err := os.ReadFile(file)
+	if err != nil {
+		slog.Error("history", "load", err)
+		return
+	}
+
+	h := history{}
+
+	if err := gob.NewDecoder(bytes.NewReader(f)).Decode(&h); err == nil && h.Version == historyVersion {
+		if h.Items != nil {
+			clipboardhistory = h.Items
+		}
+
+		return
+	}
+
+	// unversioned history of older releases
+	if err := gob.NewDecoder(bytes.NewReader(f)).Decode(&clipboardhistory); err != nil {
+		slog.Error("history", "decoding", err)
+		return
+	}
+
+	for _, v := range clipboardhistory {
+		if v.Img == "" {
+			continue
+		}
+
+		if b, err := os.ReadFile(v.Img); err == nil {
+			v.Thumb = createThumbnail(b, strings.TrimSuffix(filepath.Base(v.Img), filepath.Ext(v.Img)))
 		}
 	}
 }
 
-func cleanupImages() {
+func imageFolder() string {
 	d, _ := os.UserCacheDir()
-	folder := filepath.Join(d, "elephant", "clipboardimages")
+	return filepath.Join(d, "elephant", "clipboardimages")
+}
+
+// removeImages removes the files belonging to the item.
+func removeImages(item *Item) {
+	if item.Img != "" {
+		_ = os.Remove(item.Img)
+	}
+
+	if item.Thumb != "" {
+		_ = os.Remove(item.Thumb)
+	}
+}
+
+func cleanupImages() {
+	folder := imageFolder()
 
 	filepath.Walk(folder, func(path string, info fs.FileInfo, err error) error {
 		if info != nil && !info.IsDir() {
@@ -266,14 +415,19 @@ func cleanupImages() {
 }
 
 func saveToFile() {
-	if len(clipboardhistory) > config.MaxItems {
-		trim()
+	trim()
+
+	if !getConfig().Persist {
+		return
 	}
 
 	var b bytes.Buffer
 	encoder := gob.NewEncoder(&b)
 
-	err := encoder.Encode(clipboardhistory)
+	err := encoder.Encode(history{
+		Version: historyVersion,
+		Items:   clipboardhistory,
+	})
 	if err != nil {
 		slog.Error(Name, "encode", err)
 		return
@@ -285,14 +439,41 @@ func saveToFile() {
 		return
 	}
 
-	err = os.WriteFile(file, b.Bytes(), 0o600)
+	// write to a temp file first, so an interrupted save doesn't leave a broken history
+	tmp := file + ".tmp"
+
+	err = os.WriteFile(tmp, b.Bytes(), 0o600)
+	if err != nil {
+		slog.Error(Name, "writefile", err)
+		return
+	}
+
+	err = os.Rename(tmp, file)
 	if err != nil {
 		slog.Error(Name, "writefile", err)
 	}
 }
 
+// Stop ends watching the clipboard and saves pending changes.
+func Stop(_ context.Context) {
+	if watchCmd != nil && watchCmd.Process != nil {
+		watchCmd.Process.Kill()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// setup didn't finish
+	if getConfig() == nil {
+		return
+	}
+
+	saveToFile()
+}
+
 func handleChange() {
 	cmd := exec.Command("wl-paste", "--watch", "echo", "clipboard-changed")
+	watchCmd = cmd
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		log.Fatal("Error creating stdout pipe:", err)
@@ -305,7 +486,7 @@ func handleChange() {
 	scanner := bufio.NewScanner(stdout)
 
 	for scanner.Scan() {
-		if paused {
+		if paused || sensitive() {
 			continue
 		}
 
@@ -347,7 +528,49 @@ func getClipboardText() (string, error) {
 	return string(out), err
 }
 
-var ignoreMimetypes = []string{"x-kde-passwordManagerHint", "text/uri-list"}
+var ignoreMimetypes = []string{"text/uri-list"}
+
+// password managers mark their clipboard content with these mimetypes
+var sensitiveMimetypes = []string{"x-kde-passwordManagerHint", "password-manager-hint"}
+
+// compiled ignore_patterns of the current config, guarded by configMu
+var ignorePatterns []*regexp.Regexp
+
+func compilePatterns(c *Config) []*regexp.Regexp {
+	res := []*regexp.Regexp{}
+
+	for _, v := range c.IgnorePatterns {
+		r, err := regexp.Compile(v)
+		if err != nil {
+			slog.Error(Name, "ignore_patterns", err)
+			continue
+		}
+
+		res = append(res, r)
+	}
+
+	return res
+}
+
+// sensitive reports if the current clipboard content must not be stored,
+// because a password manager marked it or it was copied from an ignored app.
+func sensitive() bool {
+	for _, v := range getMimetypes() {
+		if slices.Contains(sensitiveMimetypes, v) {
+			slog.Debug(Name, "ignored", "password manager hint")
+			return true
+		}
+	}
+
+	if apps := getConfig().IgnoreApps; len(apps) > 0 {
+		if app := focusedApp(); app != "" && slices.Contains(apps, app) {
+			slog.Debug(Name, "ignored", app)
+			return true
+		}
+	}
+
+	return false
+}
 
 func handleSaveToFile() {
 	timer := time.NewTimer(time.Second * 5)
@@ -380,6 +603,11 @@ func updateImage(out []byte) {
 		out = buf.Bytes()
 	}
 
+	if limit := getConfig().MaxImageSize; limit > 0 && len(out) > limit*1024*1024 {
+		slog.Info(Name, "update image", "exceeds max_image_size, not storing", "size", len(out))
+		return
+	}
+
 	md5 := md5.Sum(out)
 	md5str := hex.EncodeToString(md5[:])
 
@@ -398,9 +626,10 @@ func updateImage(out []byte) {
 		ext := strings.ToLower(string(res))
 		ext = strings.TrimSpace(ext)
 
-		if file := saveImg(out, ext); file != "" {
+		if file := saveImg(out, md5str, ext); file != "" {
 			clipboardhistory[md5str] = &Item{
 				Img:   file,
+				Thumb: createThumbnail(out, md5str),
 				Time:  time.Now(),
 				State: StateEditable,
 			}
@@ -415,12 +644,22 @@ func updateText(text string) {
 		return
 	}
 
-	if config.IgnoreSymbols {
+	if getConfig().IgnoreSymbols {
 		if _, ok := symbols[text]; ok {
 			return
 		}
 	}
 
+	configMu.RLock()
+	patterns := ignorePatterns
+	configMu.RUnlock()
+
+	for _, r := range patterns {
+		if r.MatchString(strings.TrimSpace(text)) {
+			return
+		}
+	}
+
 	mt := getMimetypes()
 
 	if slices.Contains(mt, "text/_moz_htmlcontext") || slices.Contains(mt, "chromium/x-source-url") {
@@ -458,31 +697,39 @@ func updateText(text string) {
 	saveFileChan <- struct{}{}
 }
 
+// trim removes the oldest items exceeding max_items. Pinned items are kept and don't count.
 func trim() {
-	oldest := ""
-	oldestTime := time.Now()
+	items := []string{}
 
 	for k, v := range clipboardhistory {
-		if v.Time.Before(oldestTime) {
-			oldest = k
-			oldestTime = v.Time
+		if !v.Pinned {
+			items = append(items, k)
 		}
 	}
 
-	if clipboardhistory[oldest].Img != "" {
-		_ = os.Remove(clipboardhistory[oldest].Img)
+	maxItems := getConfig().MaxItems
+
+	if len(items) <= maxItems {
+		return
 	}
 
-	delete(clipboardhistory, oldest)
+	slices.SortFunc(items, func(a, b string) int {
+		return clipboardhistory[a].Time.Compare(clipboardhistory[b].Time)
+	})
+
+	for _, k := range items[:len(items)-maxItems] {
+		removeImages(clipboardhistory[k])
+		delete(clipboardhistory, k)
+	}
 }
 
-func saveImg(b []byte, ext string) string {
-	d, _ := os.UserCacheDir()
-	folder := filepath.Join(d, "elephant", "clipboardimages")
+// saveImg stores the image named by its hash, so identical images share a file.
+func saveImg(b []byte, hash, ext string) string {
+	folder := imageFolder()
 
 	os.MkdirAll(folder, 0o755)
 
-	file := filepath.Join(folder, fmt.Sprintf("%d.%s", time.Now().Unix(), ext))
+	file := filepath.Join(folder, fmt.Sprintf("%s.%s", hash, ext))
 
 	outfile, err := os.Create(file)
 	if err != nil {
@@ -510,9 +757,13 @@ const (
 	ActionLocalsend  = "localsend"
 	ActionUnpause    = "unpause"
 	ActionCopy       = "copy"
+	ActionPaste      = "paste"
+	ActionQR         = "qr"
 	ActionEdit       = "edit"
 	ActionRemove     = "remove"
 	ActionRemoveAll  = "remove_all"
+	ActionPin        = "pin"
+	ActionUnpin      = "unpin"
 	ActionImagesOnly = "show_images_only"
 	ActionTextOnly   = "show_text_only"
 	ActionCombined   = "show_combined"
@@ -549,19 +800,12 @@ func Activate(single bool, identifier, action string, query string, args string,
 			path = f.Name()
 		}
 
-		cmd := exec.Command("sh", "-c", strings.TrimSpace(fmt.Sprintf("%s %s %s", common.LaunchPrefix(""), "localsend", path)))
-
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			Setsid: true,
-		}
-
-		err := cmd.Start()
+		_, err := common.RunDetached(common.RunOpts{
+			Command: fmt.Sprintf("localsend %s", path),
+		})
 		if err != nil {
 			slog.Error(Name, "actionlocalsend", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+			common.ReportActivationError(conn, fmt.Errorf("localsend failed: %w", err))
 		}
 	case ActionPause:
 		paused = true
@@ -583,18 +827,22 @@ func Activate(single bool, identifier, action string, query string, args string,
 		}
 
 		if item.Img != "" {
-			if config.ImageEditorCmd == "" {
+			editor := getConfig().ImageEditorCmd
+
+			if editor == "" {
 				slog.Info(Name, "edit", "image_editor not set")
+				common.ReportActivationError(conn, errors.New("image_editor not set"))
 				return
 			}
 
-			toRun := strings.ReplaceAll(config.ImageEditorCmd, "%FILE%", item.Img)
+			toRun := strings.ReplaceAll(editor, "%FILE%", item.Img)
 
 			cmd := exec.Command("sh", "-c", toRun)
 
 			err := cmd.Start()
 			if err != nil {
 				slog.Error(Name, "openedit", err)
+				common.ReportActivationError(conn, fmt.Errorf("opening editor failed: %w", err))
 				return
 			} else {
 				go func() {
@@ -605,78 +853,121 @@ func Activate(single bool, identifier, action string, query string, args string,
 			return
 		}
 
-		tmpFile, err := os.CreateTemp("", "*.txt")
-		if err != nil {
-			slog.Error(Name, "edit", err)
-			return
-		}
+		editText(identifier, item.Content, conn)
+	case ActionRemove:
+		mu.Lock()
+
+		if item, ok := clipboardhistory[identifier]; ok {
+			removeImages(item)
 
-		tmpFile.Write([]byte(item.Content))
+			delete(clipboardhistory, identifier)
 
-		var run string
+			saveToFile()
+		}
 
-		if config.TextEditorCmd != "" {
-			run = strings.ReplaceAll(config.TextEditorCmd, "%FILE%", tmpFile.Name())
-		} else {
-			run = fmt.Sprintf("xdg-open file://%s", tmpFile.Name())
+		mu.Unlock()
+	case ActionRemoveAll:
+		mu.Lock()
 
-			if common.ForceTerminalForFile(tmpFile.Name()) {
-				run = common.WrapWithTerminal(run)
+		// pinned items are only removed with "force" as argument
+		if args == "force" {
+			clipboardhistory = make(map[string]*Item)
+			cleanupImages()
+		} else {
+			for k, v := range clipboardhistory {
+				if !v.Pinned {
+					removeImages(v)
+					delete(clipboardhistory, k)
+				}
 			}
 		}
 
-		cmd := exec.Command("sh", "-c", run)
-		err = cmd.Start()
-		if err != nil {
-			slog.Error(Name, "openedit", err)
+		saveToFile()
+		mu.Unlock()
+	case ActionQR:
+		item, ok := clipboardhistory[identifier]
+		if !ok {
+			common.ReportActivationError(conn, fmt.Errorf("unknown item: %s", identifier))
 			return
-		} else {
-			cmd.Wait()
+		}
 
-			b, _ := os.ReadFile(tmpFile.Name())
-			item.Content = string(b)
-			saveToFile()
+		if item.Img != "" {
+			common.ReportActivationError(conn, errors.New("images can't be encoded as qr code"))
+			return
+		}
+
+		qr, err := common.QRCode(item.Content)
+		if err != nil {
+			common.ReportActivationError(conn, err)
+			return
 		}
-	case ActionRemove:
-		mu.Lock()
 
-		if _, ok := clipboardhistory[identifier]; ok {
-			if clipboardhistory[identifier].Img != "" {
-				_ = os.Remove(clipboardhistory[identifier].Img)
+		// the path is returned as payload, "open" as argument opens it as well
+		if args == "open" {
+			if _, err := common.RunDetached(common.RunOpts{
+				Command: fmt.Sprintf("xdg-open '%s'", qr),
+			}); err != nil {
+				slog.Error(Name, "qr", err)
+				common.ReportActivationError(conn, fmt.Errorf("opening qr code failed: %w", err))
+				return
 			}
+		}
 
-			delete(clipboardhistory, identifier)
+		common.ReportActivation(conn, common.ActivationResult{
+			Message: "qr code",
+			Payload: qr,
+		})
+	case ActionPin, ActionUnpin:
+		mu.Lock()
 
+		if item, ok := clipboardhistory[identifier]; ok {
+			item.Pinned = action == ActionPin
 			saveToFile()
 		}
 
 		mu.Unlock()
-	case ActionRemoveAll:
-		mu.Lock()
-		clipboardhistory = make(map[string]*Item)
+	case ActionCopy, ActionPaste:
+		item, ok := clipboardhistory[identifier]
+		if !ok {
+			common.ReportActivationError(conn, fmt.Errorf("unknown item: %s", identifier))
+			return
+		}
 
-		saveToFile()
-		cleanupImages()
-		mu.Unlock()
-	case ActionCopy:
-		cmd := exec.Command("sh", "-c", config.Command)
+		content := []byte(item.Content)
+		mimetype := "text/plain"
 
-		item := clipboardhistory[identifier]
 		if item.Img != "" {
-			f, _ := os.ReadFile(item.Img)
-			cmd.Stdin = bytes.NewReader(f)
-		} else {
-			cmd.Stdin = strings.NewReader(item.Content)
+			b, err := os.ReadFile(item.Img)
+			if err != nil {
+				slog.Error(Name, "activate", err)
+				common.ReportActivationError(conn, fmt.Errorf("reading image failed: %w", err))
+				return
+			}
+
+			content = b
+			mimetype = mime.TypeByExtension(filepath.Ext(item.Img))
 		}
 
-		err := cmd.Start()
-		if err != nil {
+		if err := copyContent(content, mimetype); err != nil {
 			slog.Error("clipboard", "activate", err)
+			common.ReportActivationError(conn, fmt.Errorf("clipboard unavailable: %w", err))
 			return
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		}
+
+		if action == ActionPaste {
+			if err := paste(); err != nil {
+				slog.Error(Name, "paste", err)
+				common.ReportActivationError(conn, fmt.Errorf("copied, but can't paste: %w", err))
+			}
+
+			return
+		}
+
+		if item.Img == "" {
+			common.ReportActivation(conn, common.ActivationResult{
+				Message: "copied",
+				Payload: item.Content,
+			})
 		}
 	default:
 		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
@@ -684,8 +975,39 @@ func Activate(single bool, identifier, action string, query string, args string,
 	}
 }
 
-func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func copyContent(content []byte, mimetype string) error {
+	if command := getConfig().Command; command != "" {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stdin = bytes.NewReader(content)
+		return cmd.Run()
+	}
+
+	return common.CopyToClipboard(content, mimetype)
+}
+
+func Preview(identifier string) (string, string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	item, ok := clipboardhistory[identifier]
+	if !ok {
+		return "", ""
+	}
+
+	return preview(item)
+}
+
+func preview(item *Item) (string, string) {
+	if item.Img != "" {
+		return item.Img, util.PreviewTypeImage
+	}
+
+	return item.Content, util.PreviewTypeText
+}
+
+func Query(_ context.Context, conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	entries := []*pb.QueryResponse_Item{}
+	cfg := getConfig()
 
 	for k, v := range clipboardhistory {
 		switch currentMode {
@@ -699,7 +1021,15 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 			}
 		}
 
-		actions := []string{ActionCopy, ActionEdit, ActionRemove}
+		actions := []string{ActionCopy, ActionEdit, ActionRemove, ActionPin, ActionPaste}
+
+		if v.Pinned {
+			actions[3] = ActionUnpin
+		}
+
+		if v.Img == "" {
+			actions = append(actions, ActionQR)
+		}
 
 		if hasLocalsend {
 			actions = append(actions, ActionLocalsend)
@@ -712,14 +1042,33 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 			Type:       pb.QueryResponse_REGULAR,
 			Actions:    actions,
 			Provider:   Name,
+			Metadata: map[string]string{
+				"time_unix": strconv.FormatInt(v.Time.Unix(), 10),
+				"kind":      "text",
+			},
 		}
 
 		if v.Img != "" {
-			e.Preview = v.Img
-			e.PreviewType = util.PreviewTypeFile
+			e.Metadata["kind"] = "image"
+			e.Icon = v.Thumb
+
+			if e.Icon == "" {
+				e.Icon = v.Img
+			}
+		}
+
+		if v.State != "" {
+			e.Metadata["state"] = v.State
+		}
+
+		if v.Pinned {
+			e.Metadata["pinned"] = "true"
+		}
+
+		if cfg.LazyPreviews {
+			e.PreviewType = util.PreviewTypeLazy
 		} else {
-			e.Preview = v.Content
-			e.PreviewType = util.PreviewTypeText
+			e.Preview, e.PreviewType = preview(v)
 		}
 
 		if query != "" {
@@ -732,7 +1081,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 				Start:     start,
 			}
 
-			if e.Score > config.MinScore {
+			if e.Score > cfg.MinScoreFor(query) {
 				entries = append(entries, e)
 			}
 		} else {
@@ -742,6 +1091,15 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 
 	if query == "" {
 		slices.SortStableFunc(entries, func(a, b *pb.QueryResponse_Item) int {
+			// pinned items first
+			if pinnedA, pinnedB := a.Metadata["pinned"] != "", b.Metadata["pinned"] != ""; pinnedA != pinnedB {
+				if pinnedA {
+					return -1
+				}
+
+				return 1
+			}
+
 			ta, _ := time.Parse(time.RFC1123Z, a.Subtext)
 			tb, _ := time.Parse(time.RFC1123Z, b.Subtext)
 
@@ -770,11 +1128,11 @@ func getMimetypes() []string {
 }
 
 func Icon() string {
-	return config.Icon
+	return getConfig().Icon
 }
 
 func HideFromProviderlist() bool {
-	return config.HideFromProviderlist
+	return getConfig().HideFromProviderlist
 }
 
 func State(provider string) *pb.ProviderStateResponse {
@@ -789,6 +1147,20 @@ func State(provider string) *pb.ProviderStateResponse {
 		actions = append(actions, ActionPause)
 	}
 
+	mu.Lock()
+
+	pinned := 0
+
+	for _, v := range clipboardhistory {
+		if v.Pinned {
+			pinned++
+		}
+	}
+
+	mu.Unlock()
+
+	states = append(states, fmt.Sprintf("%d pinned / %d items", pinned, len(clipboardhistory)))
+
 	if len(clipboardhistory) > 0 {
 		actions = append(actions, ActionRemoveAll)
 	}
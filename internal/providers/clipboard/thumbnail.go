@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// longer side of thumbnails in pixels
+const thumbnailSize = 256
+
+// larger images aren't decoded, as that needs 4 bytes per pixel
+const thumbnailMaxPixels = 40_000_000
+
+// createThumbnail stores a downscaled png of the image and returns its path.
+// Returns an empty string for small or unsupported images, the image itself
+// is used as icon then.
+func createThumbnail(b []byte, name string) string {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		slog.Debug(Name, "thumbnail", err)
+		return ""
+	}
+
+	if int64(cfg.Width)*int64(cfg.Height) > thumbnailMaxPixels {
+		slog.Info(Name, "thumbnail", "image too large", "width", cfg.Width, "height", cfg.Height)
+		return ""
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		slog.Debug(Name, "thumbnail", err)
+		return ""
+	}
+
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	if w <= thumbnailSize && h <= thumbnailSize {
+		return ""
+	}
+
+	tw := max(w*thumbnailSize/max(w, h), 1)
+	th := max(h*thumbnailSize/max(w, h), 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+
+	for y := range th {
+		for x := range tw {
+			dst.Set(x, y, src.At(bounds.Min.X+x*w/tw, bounds.Min.Y+y*h/th))
+		}
+	}
+
+	folder := filepath.Join(imageFolder(), "thumbnails")
+
+	if err := os.MkdirAll(folder, 0o755); err != nil {
+		slog.Error(Name, "thumbnail", err)
+		return ""
+	}
+
+	file := filepath.Join(folder, name+".png")
+
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, dst); err != nil {
+		slog.Error(Name, "thumbnail", err)
+		return ""
+	}
+
+	if err := os.WriteFile(file, buf.Bytes(), 0o600); err != nil {
+		slog.Error(Name, "thumbnail", err)
+		return ""
+	}
+
+	return file
+}
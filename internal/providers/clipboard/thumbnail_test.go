@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"os"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// withSize changes the size in the header of a png, keeping the small pixel data.
+func withSize(b []byte, w, h uint32) []byte {
+	res := bytes.Clone(b)
+
+	// signature, then length and type of the IHDR chunk
+	ihdr := res[8+8 : 8+8+13]
+	binary.BigEndian.PutUint32(ihdr[0:], w)
+	binary.BigEndian.PutUint32(ihdr[4:], h)
+	binary.BigEndian.PutUint32(res[8+8+13:], crc32.ChecksumIEEE(res[8+4:8+8+13]))
+
+	return res
+}
+
+func TestCreateThumbnail(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if file := createThumbnail(encodePNG(t, 100, 50), "small"); file != "" {
+		t.Errorf("got thumbnail %s for a small image", file)
+	}
+
+	file := createThumbnail(encodePNG(t, 1024, 512), "large")
+	if file == "" {
+		t.Fatal("no thumbnail for a large image")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cfg, err := png.DecodeConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Width != thumbnailSize || cfg.Height != thumbnailSize/2 {
+		t.Errorf("got %dx%d, want %dx%d", cfg.Width, cfg.Height, thumbnailSize, thumbnailSize/2)
+	}
+
+	if file := createThumbnail([]byte("not an image"), "invalid"); file != "" {
+		t.Errorf("got thumbnail %s for invalid data", file)
+	}
+}
+
+func TestCreateThumbnailPixelBudget(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	b := withSize(encodePNG(t, 1, 1), 100_000, 100_000)
+
+	if cfg, err := png.DecodeConfig(bytes.NewReader(b)); err != nil || cfg.Width != 100_000 {
+		t.Fatalf("crafted header invalid: %v", err)
+	}
+
+	if file := createThumbnail(b, "huge"); file != "" {
+		t.Errorf("got thumbnail %s for an image exceeding the budget", file)
+	}
+}
@@ -7,11 +7,10 @@ import (
 	"log/slog"
 	"net"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
-	"syscall"
 
 	"github.com/abenz1267/elephant/v2/pkg/common"
 	"github.com/abenz1267/elephant/v2/pkg/common/history"
@@ -26,9 +25,18 @@ const (
 	ActionUnpin       = "unpin"
 	ActionStart       = "start"
 	ActionNewInstance = "new_instance"
+	// ActionResetHistory clears history and recorded usage
+	ActionResetHistory = "reset_history"
+	// ActionAppAction followed by the action of the desktop file starts it
+	ActionAppAction = "app_action:"
 )
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if after, ok := strings.CutPrefix(action, ActionAppAction); ok {
+		identifier = fmt.Sprintf("%s:%s", identifier, after)
+		action = ActionStart
+	}
+
 	switch action {
 	case ActionPinUp:
 		movePin(identifier, false)
@@ -40,16 +48,30 @@ func Activate(single bool, identifier, action string, query string, args string,
 	case history.ActionDelete:
 		h.Remove(identifier)
 		return
+	case ActionResetHistory:
+		h.Clear()
+		common.ResetUsage(Name)
+		return
 	case ActionStart, ActionNewInstance:
 		toRun := ""
-		prefix := common.LaunchPrefix(config.LaunchPrefix)
 
 		parts := strings.Split(identifier, ":")
 
+		filesMu.RLock()
+		f, ok := files[parts[0]]
+		filesMu.RUnlock()
+
+		if !ok {
+			err := fmt.Errorf("unknown desktop file: %s", parts[0])
+			slog.Error(Name, "activate", err)
+			common.ReportActivationError(conn, err)
+			return
+		}
+
 		isAction := false
 
 		if len(parts) == 2 {
-			for _, v := range files[parts[0]].Actions {
+			for _, v := range f.Actions {
 				if v.Action == parts[1] {
 					toRun = v.Exec
 					isAction = true
@@ -57,17 +79,15 @@ func Activate(single bool, identifier, action string, query string, args string,
 				}
 			}
 		} else {
-			toRun = files[parts[0]].Exec
+			toRun = f.Exec
 		}
 
 		if config.WindowIntegration && wlr.IsSetup && action != ActionNewInstance {
 			if !isAction || !config.WindowIntegrationIgnoreActions {
-				if id, ok := appHasWindow(files[parts[0]]); ok {
+				if id, ok := appHasWindow(f); ok {
 					if err := wlr.Activate(id); err == nil {
-
-						if config.History {
-							h.Save(query, identifier)
-						}
+						saveHistory(query, identifier)
+						recordLaunch(identifier)
 
 						return
 					} else {
@@ -77,43 +97,37 @@ func Activate(single bool, identifier, action string, query string, args string,
 			}
 		}
 
-		if files[parts[0]].Terminal {
-			toRun = common.WrapWithTerminal(toRun)
-		}
-
-		cmd := exec.Command("sh", "-c", strings.TrimSpace(fmt.Sprintf("%s %s %s", prefix, toRun, args)))
-
-		if files[parts[0]].Path != "" {
-			cmd.Dir = files[parts[0]].Path
-		}
-
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			Setsid: true,
-		}
-
 		if config.WMIntegration && wmi != nil {
-			appid := files[parts[0]].StartupWMClass
+			appid := f.StartupWMClass
 
 			if !slices.Contains(config.SingleInstanceApps, appid) || !slices.Contains(wmi.GetCurrentWindows(), appid) {
 				go wmi.MoveToWorkspace(wmi.GetWorkspace(), appid)
 			}
 		}
 
-		slog.Debug(Name, "activate", cmd.String())
+		opts := common.RunOpts{
+			Command:     strings.TrimSpace(fmt.Sprintf("%s %s", toRun, args)),
+			Prefix:      config.LaunchPrefix,
+			Terminal:    f.Terminal,
+			TerminalCmd: config.Terminal,
+			Dir:         f.Path,
+		}
+
+		slog.Debug(Name, "activate", opts.Command)
 
-		err := cmd.Start()
+		pid, err := common.RunDetached(opts)
 		if err != nil {
 			slog.Error(Name, "activate", identifier, "error", err)
+			common.ReportActivationError(conn, err)
 			return
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
 		}
 
-		if config.History {
-			h.Save(query, identifier)
-		}
+		common.ReportActivation(conn, common.ActivationResult{
+			Payload: strconv.Itoa(pid),
+		})
+
+		saveHistory(query, identifier)
+		recordLaunch(identifier)
 
 		slog.Info(Name, "activated", identifier)
 	default:
@@ -122,6 +136,61 @@ func Activate(single bool, identifier, action string, query string, args string,
 	}
 }
 
+// saveHistory records the usage, for actions the application is counted as well.
+func saveHistory(query, identifier string) {
+	if !config.History {
+		return
+	}
+
+	h.Save(query, identifier)
+
+	if parent, _, ok := strings.Cut(identifier, ":"); ok {
+		h.Save(query, parent)
+	}
+}
+
+// pinList has the configured pins first, followed by the ones set via the pin action. Requires pinsMu.
+func pinList() []string {
+	if len(config.Pinned) == 0 {
+		return pins
+	}
+
+	res := slices.Clone(config.Pinned)
+
+	for _, v := range pins {
+		if !slices.Contains(res, v) {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+// pinActions returns the pin related actions, configured pins can't be changed. Requires pinsMu.
+func pinActions(identifier string) []string {
+	if slices.Contains(config.Pinned, identifier) {
+		return nil
+	}
+
+	i := slices.Index(pins, identifier)
+
+	if i == -1 {
+		return []string{ActionPin}
+	}
+
+	res := []string{ActionUnpin}
+
+	if i != 0 {
+		res = append(res, ActionPinUp)
+	}
+
+	if i != len(pins)-1 {
+		res = append(res, ActionPinDown)
+	}
+
+	return res
+}
+
 func movePin(identifier string, down bool) {
 	pinsMu.Lock()
 	defer pinsMu.Unlock()
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+// parsed desktop files, keyed by path. Entries are reused as long as the modification time matches.
+type fileCache struct {
+	// Key invalidates the whole cache, if anything the parsing depends on changed
+	Key   string
+	Files map[string]cachedFile
+}
+
+type cachedFile struct {
+	ModTime time.Time
+	File    DesktopFile
+}
+
+var (
+	cached  fileCache
+	parsed  = make(map[string]cachedFile)
+	cacheMu sync.Mutex
+)
+
+func cacheFile() string {
+	return common.CacheFile(fmt.Sprintf("%s_files.gob", Name))
+}
+
+func cacheKey() string {
+	return strings.Join([]string{langLocale, regionLocale, desktop, config.IconPlaceholder}, ";")
+}
+
+func loadCache() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	cached = fileCache{}
+	parsed = make(map[string]cachedFile)
+
+	file := cacheFile()
+
+	if !common.FileExists(file) {
+		return
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		slog.Error(Name, "cache load", err)
+		return
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&cached); err != nil {
+		slog.Error(Name, "cache decoding", err)
+		cached = fileCache{}
+		return
+	}
+
+	if cached.Key != cacheKey() {
+		cached = fileCache{}
+	}
+}
+
+// parseCached parses the desktop file, unless it's cached and unchanged.
+func parseCached(path string) (*DesktopFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	c, ok := cached.Files[path]
+	cacheMu.Unlock()
+
+	if !ok || !c.ModTime.Equal(info.ModTime()) {
+		f, err := parseFile(path, langLocale, regionLocale)
+		if err != nil {
+			return nil, err
+		}
+
+		c = cachedFile{
+			ModTime: info.ModTime(),
+			File:    *f,
+		}
+	}
+
+	cacheMu.Lock()
+	parsed[path] = c
+	cacheMu.Unlock()
+
+	res := c.File
+
+	return &res, nil
+}
+
+func uncache(path string) {
+	cacheMu.Lock()
+	delete(parsed, path)
+	cacheMu.Unlock()
+}
+
+// writeCache stores the files parsed since the start, files not found anymore are dropped.
+func writeCache() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	cached = fileCache{
+		Key:   cacheKey(),
+		Files: parsed,
+	}
+
+	var b bytes.Buffer
+
+	if err := gob.NewEncoder(&b).Encode(cached); err != nil {
+		slog.Error(Name, "cache encode", err)
+		return
+	}
+
+	file := cacheFile()
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		slog.Error(Name, "cache createdirs", err)
+		return
+	}
+
+	if err := os.WriteFile(file, b.Bytes(), 0o600); err != nil {
+		slog.Error(Name, "cache writefile", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -28,9 +29,13 @@ var (
 	dirs          []string
 )
 
+// events are collected for this long, package managers touch files several times
+const refreshDebounce = 500 * time.Millisecond
+
 func loadFiles() {
 	start := time.Now()
 	setVars()
+	loadCache()
 	conf := fastwalk.Config{
 		Follow: true,
 	}
@@ -44,6 +49,7 @@ func loadFiles() {
 
 	for _, root := range dirs {
 		if _, err := os.Stat(root); err != nil {
+			watchParent(root)
 			continue
 		}
 
@@ -53,6 +59,8 @@ func loadFiles() {
 		}
 	}
 
+	writeCache()
+
 	fileCount := len(files)
 	slog.Info(Name, "files", fileCount, "time", time.Since(start))
 
@@ -69,7 +77,29 @@ func setVars() {
 
 	getLocale()
 
-	dirs = xdg.ApplicationDirs
+	dirs = slices.Clone(xdg.ApplicationDirs)
+
+	// flatpak exports are usually part of XDG_DATA_DIRS, but only after a re-login once flatpak got installed
+	for _, v := range []string{
+		filepath.Join(xdg.DataHome, "flatpak", "exports", "share", "applications"),
+		"/var/lib/flatpak/exports/share/applications",
+	} {
+		if !slices.Contains(dirs, v) {
+			dirs = append(dirs, v)
+		}
+	}
+}
+
+func blacklisted(path string) bool {
+	check := strings.TrimSuffix(filepath.Base(path), ".desktop")
+
+	for _, v := range br {
+		if v.MatchString(check) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func walkFunction(path string, d fs.DirEntry, err error) error {
@@ -77,14 +107,8 @@ func walkFunction(path string, d fs.DirEntry, err error) error {
 		return err
 	}
 
-	if filepath.Ext(path) == ".desktop" {
-		check := strings.TrimSuffix(filepath.Base(path), ".desktop")
-
-		for _, v := range br {
-			if v.MatchString(check) {
-				return nil
-			}
-		}
+	if filepath.Ext(path) == ".desktop" && blacklisted(path) {
+		return nil
 	}
 
 	filesMu.RLock()
@@ -106,6 +130,7 @@ func walkFunction(path string, d fs.DirEntry, err error) error {
 	return err
 }
 
+// trackSymlinks requires filesMu.
 func trackSymlinks(filename string) {
 	// for all intents and purposes, filename is the symlink
 	// targetPath is what it resolves to.
@@ -115,8 +140,7 @@ func trackSymlinks(filename string) {
 	}
 
 	// setup two-way tracking
-	if realToSymlink[targetPath] == nil {
-		realToSymlink[targetPath] = make([]string, 0)
+	if !slices.Contains(realToSymlink[targetPath], filename) {
 		realToSymlink[targetPath] = append(realToSymlink[targetPath], filename)
 	}
 
@@ -142,16 +166,42 @@ func addDirToWatcher(dir string, watchedDirs map[string]bool) {
 	watchedDirs[dir] = true
 }
 
+// watchParent watches the closest existing parent of a missing applications directory, to notice its creation.
+func watchParent(root string) {
+	for dir := filepath.Dir(root); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if fileExists(dir) {
+			addDirToWatcher(dir, watchedDirs)
+			return
+		}
+	}
+}
+
 func watchFiles() {
 	defer watcher.Close()
 
+	pending := make(map[string]struct{})
+
+	timer := time.NewTimer(refreshDebounce)
+	timer.Stop()
+
 	for {
 		select {
 		case event, ok := <-watcher.Events:
 			if !ok {
 				return
 			}
-			handleFileEvent(event)
+
+			if changed := handleFileEvent(event); len(changed) > 0 {
+				for _, v := range changed {
+					pending[v] = struct{}{}
+				}
+
+				timer.Reset(refreshDebounce)
+			}
+
+		case <-timer.C:
+			refresh(pending)
+			pending = make(map[string]struct{})
 
 		case err, ok := <-watcher.Errors:
 			if !ok {
@@ -171,124 +221,184 @@ func checkSubdirOfXDG(subdir string) bool {
 	return false
 }
 
-func handleFileEvent(event fsnotify.Event) {
+// checkParentOfXDG checks if the directory leads to an applications directory.
+func checkParentOfXDG(dir string) bool {
+	for _, v := range dirs {
+		if strings.HasPrefix(v, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleFileEvent returns the desktop files affected by the event.
+func handleFileEvent(event fsnotify.Event) []string {
 	slog.Debug(Name, "file_system_event", event)
-	if filepath.Ext(event.Name) != ".desktop" {
-		// Handle directory creation to watch new subdirectories
 
+	if event.Op == fsnotify.Chmod {
+		return nil
+	}
+
+	if filepath.Ext(event.Name) != ".desktop" {
+		// Handle directory creation to watch new subdirectories, f.e. a newly created ~/.local/share/applications
 		if event.Op&fsnotify.Create == fsnotify.Create {
 			if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+				return addNewDir(event.Name)
+			}
+		}
 
-				// Don't track new subdirs of a dir we are only tracking for origin files
-				if !checkSubdirOfXDG(event.Name) {
-					return
-				}
+		return nil
+	}
 
-				if err := watcher.Add(event.Name); err != nil {
-					slog.Warn(Name, "watcher_add_new", err, "dir", event.Name)
-				}
+	if blacklisted(event.Name) {
+		return nil
+	}
+
+	return []string{event.Name}
+}
+
+// addNewDir watches a created directory, returning the desktop files it already contains.
+func addNewDir(dir string) []string {
+	res := []string{}
+
+	// Don't track new subdirs of a dir we are only tracking for origin files
+	if !checkSubdirOfXDG(dir) && !checkParentOfXDG(dir) {
+		return res
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			if !checkSubdirOfXDG(path) && !checkParentOfXDG(path) {
+				return filepath.SkipDir
 			}
+
+			addDirToWatcher(path, watchedDirs)
+
+			return nil
+		}
+
+		if filepath.Ext(path) == ".desktop" && checkSubdirOfXDG(path) && !blacklisted(path) {
+			res = append(res, path)
 		}
-		return
-	}
 
-	switch {
-	case event.Op&fsnotify.Create == fsnotify.Create:
-		handleFileCreate(event.Name)
-	case event.Op&fsnotify.Write == fsnotify.Write:
-		handleFileUpdate(event.Name)
-	case event.Op&fsnotify.Remove == fsnotify.Remove:
-		handleFileRemove(event.Name)
-	case event.Op&fsnotify.Rename == fsnotify.Rename:
-		handleFileRemove(event.Name)
+		return nil
+	})
+	if err != nil {
+		slog.Warn(Name, "watcher_add_new", err, "dir", dir)
 	}
 
-	handlers.ProviderUpdated <- Name
+	return res
 }
 
-func handleFileCreate(path string) {
-	clone := realToSymlink[path]
-	_, sym := isSymlink(path)
-	defer slog.Debug(Name, "file_created", path)
-	if !sym {
-		if clone != nil {
-			for _, symedFile := range clone {
-				addNewEntry(symedFile)
+// refresh re-parses the changed desktop files and notifies subscribers once.
+func refresh(paths map[string]struct{}) {
+	for path := range paths {
+		filesMu.RLock()
+		symlinks := slices.Clone(realToSymlink[path])
+		filesMu.RUnlock()
+
+		// the file is the origin of symlinks in the applications directories
+		if len(symlinks) > 0 {
+			for _, v := range symlinks {
+				refreshEntry(v)
 			}
-			return
+
+			continue
 		}
-		if !checkSubdirOfXDG(path) {
-			return
+
+		if checkSubdirOfXDG(path) {
+			refreshEntry(path)
 		}
 	}
 
-	addNewEntry(path)
+	writeCache()
+
+	slog.Debug(Name, "refreshed", len(paths))
+
+	handlers.ProviderUpdated <- Name
 }
 
-func handleFileUpdate(path string) {
-	clone := realToSymlink[path]
+// refreshEntry updates the entry for the desktop file at path. The file might be gone, f.e. renamed
+// away, or shadowed by the same desktop file in a directory with higher priority.
+func refreshEntry(path string) {
+	if !fileExists(path) {
+		handleFileRemove(path)
+	}
 
-	defer slog.Debug(Name, "file_updated", path)
+	if resolved := resolveEntry(path); resolved != "" {
+		addNewEntry(resolved)
+		slog.Debug(Name, "file_updated", resolved)
+	}
+}
 
-	_, sym := isSymlink(path)
-	if !sym {
-		if clone != nil {
-			for _, symedFile := range clone {
-				addNewEntry(symedFile)
-			}
-			return
+// resolveEntry returns the desktop file with the same id as path, from the first directory having it.
+func resolveEntry(path string) string {
+	for _, root := range dirs {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
 		}
-		if !checkSubdirOfXDG(path) {
-			return
+
+		for _, v := range dirs {
+			if candidate := filepath.Join(v, rel); fileExists(candidate) {
+				return candidate
+			}
 		}
+
+		return ""
 	}
-	addNewEntry(path)
+
+	return ""
 }
 
 func handleFileRemove(path string) {
-	originPath, sym := isSymlink(path)
 	defer slog.Debug(Name, "file_removed", path)
 
+	uncache(path)
+
 	filesMu.Lock()
+	defer filesMu.Unlock()
+
 	delete(files, filepath.Base(path))
-	filesMu.Unlock()
 
-	if sym {
+	if originPath, sym := symlinkToReal[path]; sym {
 		delete(symlinkToReal, path)
 
-		for i, s := range realToSymlink[originPath] {
-			if s == path {
-				realToSymlink[originPath] = append(realToSymlink[originPath][:i], realToSymlink[originPath][i+1:]...)
-			}
-		}
+		realToSymlink[originPath] = slices.DeleteFunc(realToSymlink[originPath], func(s string) bool {
+			return s == path
+		})
+
 		if len(realToSymlink[originPath]) == 0 {
 			delete(realToSymlink, originPath)
 		}
 	}
-
-	if realToSymlink[path] != nil {
-		for _, symedFile := range realToSymlink[path] {
-			delete(symlinkToReal, symedFile)
-		}
-	}
 }
 
 func addNewEntry(path string) {
-	if origin, sym := isSymlink(path); sym {
-		// check the file the symlink points to actually exists
-		// otherwise it'll panic if you point to a location that's invalid
-		trackSymlinks(path)
-		if !fileExists(origin) {
-			return
-		}
+	filesMu.Lock()
+	trackSymlinks(path)
+	filesMu.Unlock()
+
+	// check the file the symlink points to actually exists
+	// otherwise it'll panic if you point to a location that's invalid
+	if origin, sym := isSymlink(path); sym && !fileExists(origin) {
+		return
 	}
 
-	filesMu.Lock()
-	if f, err := parseFile(path, langLocale, regionLocale); err == nil {
-		files[filepath.Base(path)] = f
-	} else {
+	f, err := parseCached(path)
+	if err != nil {
 		slog.Error(Name, "parsing", err)
+		return
 	}
+
+	applyOverride(filepath.Base(path), f)
+
+	filesMu.Lock()
+	files[filepath.Base(path)] = f
 	filesMu.Unlock()
 }
 
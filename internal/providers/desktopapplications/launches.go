@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+// recordLaunch records the usage of the item, for actions the application is counted as well.
+func recordLaunch(identifier string) {
+	common.RecordUsage(Name, identifier)
+
+	if parent, _, ok := strings.Cut(identifier, ":"); ok {
+		common.RecordUsage(Name, parent)
+	}
+}
+
+// frecency is the global usage score, combining how often and how recently an item was launched.
+func frecency(identifier string) int32 {
+	if !config.Frecency {
+		return 0
+	}
+
+	return common.UsageScore(Name, identifier)
+}
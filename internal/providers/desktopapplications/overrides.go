@@ -0,0 +1,60 @@
+package main
+
+import "strings"
+
+type Override struct {
+	Name     string   `koanf:"name" desc:"replaces the name" default:""`
+	Icon     string   `koanf:"icon" desc:"replaces the icon" default:""`
+	Hide     bool     `koanf:"hide" desc:"hides the application" default:"false"`
+	Exec     string   `koanf:"exec" desc:"replaces the command" default:""`
+	Keywords []string `koanf:"keywords" desc:"additional keywords, matched with a lower weight than the name" default:"<empty>"`
+}
+
+// applyOverride changes the parsed desktop file as configured for its desktop-id.
+func applyOverride(id string, f *DesktopFile) {
+	o, ok := config.Overrides[strings.TrimSuffix(id, ".desktop")]
+	if !ok {
+		o, ok = config.Overrides[id]
+	}
+
+	if !ok {
+		return
+	}
+
+	// actions share the slice with the cached file
+	actions := make([]Data, len(f.Actions))
+	copy(actions, f.Actions)
+	f.Actions = actions
+
+	if o.Name != "" {
+		f.Name = o.Name
+
+		for k := range f.Actions {
+			f.Actions[k].Parent = o.Name
+		}
+	}
+
+	if o.Icon != "" {
+		for k, v := range f.Actions {
+			if v.Icon == f.Icon {
+				f.Actions[k].Icon = o.Icon
+			}
+		}
+
+		f.Icon = o.Icon
+	}
+
+	if o.Hide {
+		f.Hidden = true
+
+		for k := range f.Actions {
+			f.Actions[k].Hidden = true
+		}
+	}
+
+	if o.Exec != "" {
+		f.Exec = o.Exec
+	}
+
+	f.OverrideKeywords = o.Keywords
+}
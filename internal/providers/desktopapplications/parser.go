@@ -28,6 +28,8 @@ type Data struct {
 	OnlyShowIn     []string
 	NotShowIn      []string
 	Keywords       []string
+	// OverrideKeywords are set by the overrides in the config
+	OverrideKeywords []string
 }
 
 func parseFile(path, l, ll string) (*DesktopFile, error) {
@@ -35,8 +37,7 @@ func parseFile(path, l, ll string) (*DesktopFile, error) {
 
 	data, err := os.ReadFile(path)
 	if err != nil {
-		slog.Error(Name, "parseFile", err)
-		os.Exit(1)
+		return nil, err
 	}
 
 	parts := splitIntoParsebles(data)
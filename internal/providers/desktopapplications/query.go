@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net"
@@ -8,6 +9,7 @@ import (
 	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/abenz1267/elephant/v2/pkg/common"
 	"github.com/abenz1267/elephant/v2/pkg/common/history"
@@ -16,17 +18,29 @@ import (
 
 var desktop = os.Getenv("XDG_CURRENT_DESKTOP")
 
-func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func Query(_ context.Context, conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	start := time.Now()
-	entries := make([]*pb.QueryResponse_Item, 0, len(files)*2) // Estimate for entries + action
 
 	alias := ""
 	if val, ok := config.Aliases[query]; ok {
 		alias = val
 	}
 
+	filesMu.RLock()
+	defer filesMu.RUnlock()
+
+	pinsMu.RLock()
+	defer pinsMu.RUnlock()
+
+	allPins := pinList()
+
+	entries := make([]*pb.QueryResponse_Item, 0, len(files)*2) // Estimate for entries + action
+
+	// highest score that got boosted by usage, exact name matches are placed above it
+	var maxBoosted int32
+
 	for k, v := range files {
-		if len(v.NotShowIn) != 0 && slices.Contains(v.NotShowIn, desktop) || len(v.OnlyShowIn) != 0 && !slices.Contains(v.OnlyShowIn, desktop) || v.Hidden || v.NoDisplay {
+		if isHidden(v) {
 			continue
 		}
 
@@ -47,6 +61,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 				Actions:    actions,
 				Provider:   Name,
 				Score:      1_000_000,
+				DedupKey:   common.ExecutableKey(v.Exec),
 			})
 			continue
 		}
@@ -68,27 +83,32 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 			}
 		}
 
-		var usageScore int32
-		if config.History && score > config.MinScore || (query == "" && config.HistoryWhenEmpty) {
+		var usageScore, boost int32
+		if config.History && score > config.MinScoreFor(query) || (query == "" && config.HistoryWhenEmpty) {
 			usageScore = h.CalcUsageScore(query, k)
-			score = score + usageScore
+			boost = usageScore
+		}
+
+		// both count the same launches, so frecency replaces the history boost
+		if config.Frecency && (score >= config.MinScoreFor(query) || query == "") {
+			boost = frecency(k)
 		}
 
-		pinned := false
+		score = score + boost
+
+		if boost != 0 && !strings.EqualFold(v.Name, query) {
+			maxBoosted = max(maxBoosted, score)
+		}
 
-		pinsMu.RLock()
-		if query == "" {
-			index := slices.Index(pins, k)
+		pinIndex := slices.Index(allPins, k)
+		pinned := pinIndex != -1
 
-			if index != -1 {
-				pinned = true
-				score = 1000000 - int32(index)
-			}
+		if query == "" && pinned {
+			score = 1000000 - int32(pinIndex)
 		}
-		pinsMu.RUnlock()
 
 		if score != 0 || usageScore != 0 || config.ShowActions && config.ShowGeneric || !config.ShowActions || (config.ShowActions && len(v.Actions) == 0) || query == "" {
-			if score >= config.MinScore || query == "" {
+			if score >= config.MinScoreFor(query) || query == "" {
 				state := []string{}
 				a := []string{ActionStart}
 
@@ -107,23 +127,18 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 					state = append(state, "unpinned")
 				}
 
-				pinsMu.RLock()
-				if slices.Contains(pins, k) {
-					a = append(a, ActionUnpin)
+				a = append(a, pinActions(k)...)
 
-					i := slices.Index(pins, k)
+				var metadata map[string]string
 
-					if i != 0 {
-						a = append(a, ActionPinUp)
-					}
+				if config.ActionsAsList && len(v.Actions) > 0 {
+					metadata = make(map[string]string, len(v.Actions))
 
-					if i != len(pins)-1 {
-						a = append(a, ActionPinDown)
+					for _, da := range v.Actions {
+						a = append(a, ActionAppAction+da.Action)
+						metadata[ActionAppAction+da.Action] = da.Name
 					}
-				} else {
-					a = append(a, ActionPin)
 				}
-				pinsMu.RUnlock()
 
 				if query != "" && config.WindowIntegration && config.ScoreOpenWindows {
 					if _, ok := appHasWindow(v); ok {
@@ -141,6 +156,8 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 					State:      state,
 					Provider:   Name,
 					Score:      score,
+					DedupKey:   common.ExecutableKey(v.Exec),
+					Metadata:   metadata,
 					Fuzzyinfo: &pb.QueryResponse_Item_FuzzyInfo{
 						Start:     fs,
 						Field:     field,
@@ -154,6 +171,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 		if config.ShowActions {
 			for _, a := range v.Actions {
 				identifier := fmt.Sprintf("%s:%s", k, a.Action)
+				text := fmt.Sprintf("%s: %s", v.Name, a.Name)
 
 				actions := []string{ActionStart}
 
@@ -166,7 +184,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 						Identifier: identifier,
 						Score:      1_000_000,
 						Actions:    actions,
-						Text:       a.Name,
+						Text:       text,
 						Type:       pb.QueryResponse_REGULAR,
 						Subtext:    v.Name,
 						Icon:       a.Icon,
@@ -191,36 +209,49 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 						field = "subtext"
 					}
 
+					// the text is prefixed with the application
+					if field == "text" {
+						offset := int32(utf8.RuneCountInString(text) - utf8.RuneCountInString(a.Name))
+						fs += offset
+
+						for i := range positions {
+							positions[i] += offset
+						}
+					}
+
 					if config.ActionMinScore > 0 {
-						if score < config.MinScore {
+						if score < config.MinScoreFor(query) {
 							continue
 						}
 					}
 				}
 
-				var usageScore int32
+				var usageScore, boost int32
 				if config.History {
-					if score > config.MinScore || query == "" && config.HistoryWhenEmpty {
+					if score > config.MinScoreFor(query) || query == "" && config.HistoryWhenEmpty {
 						usageScore = h.CalcUsageScore(query, identifier)
-						score = score + usageScore
+						boost = usageScore
 					}
 				}
 
-				pinned := false
+				if config.Frecency && (score >= config.MinScoreFor(query) || query == "") {
+					boost = frecency(identifier)
+				}
+
+				if boost != 0 {
+					score = score + boost
+					maxBoosted = max(maxBoosted, score)
+				}
 
-				pinsMu.RLock()
-				if query == "" {
-					i := slices.Index(pins, identifier)
+				pinIndex := slices.Index(allPins, identifier)
+				pinned := pinIndex != -1
 
-					if i != -1 {
-						pinned = true
-						score = 1000000 - int32(i)
-					}
+				if query == "" && pinned {
+					score = 1000000 - int32(pinIndex)
 				}
-				pinsMu.RUnlock()
 
 				if (query == "" && config.ShowActionsWithoutQuery) || query != "" || usageScore != 0 || score != 0 {
-					if score >= config.MinScore || query == "" {
+					if score >= config.MinScoreFor(query) || query == "" {
 						state := []string{}
 
 						if usageScore != 0 {
@@ -228,31 +259,19 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 							actions = append(actions, history.ActionDelete)
 						}
 
-						pinsMu.RLock()
 						if pinned {
 							state = append(state, "pinned")
-							actions = append(actions, ActionUnpin)
-
-							i := slices.Index(pins, identifier)
-
-							if i != 0 {
-								actions = append(actions, ActionPinUp)
-							}
-
-							if i != len(pins)-1 {
-								actions = append(actions, ActionPinDown)
-							}
 						} else {
 							state = append(state, "unpinned")
-							actions = append(actions, ActionPin)
 						}
-						pinsMu.RUnlock()
+
+						actions = append(actions, pinActions(identifier)...)
 
 						entries = append(entries, &pb.QueryResponse_Item{
 							Identifier: identifier,
 							Score:      score,
 							Actions:    actions,
-							Text:       a.Name,
+							Text:       text,
 							Type:       pb.QueryResponse_REGULAR,
 							State:      state,
 							Subtext:    subtext,
@@ -271,11 +290,80 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 
 	}
 
+	if query != "" && len(entries) == 0 && config.SubstringFallback {
+		entries = substringMatches(query)
+	}
+
+	// usage must not place another application above the one named exactly like the query
+	if query != "" && maxBoosted > 0 {
+		for _, e := range entries {
+			if e.Score <= maxBoosted && e.Score < 1_000_000 && strings.EqualFold(e.Text, query) {
+				e.Score = maxBoosted + 1
+			}
+		}
+	}
+
 	slog.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
 
+func isHidden(v *DesktopFile) bool {
+	return len(v.NotShowIn) != 0 && slices.Contains(v.NotShowIn, desktop) || len(v.OnlyShowIn) != 0 && !slices.Contains(v.OnlyShowIn, desktop) || v.Hidden || v.NoDisplay
+}
+
+// substringMatches finds applications whose name contains the query, for when
+// nothing scored high enough. Requires filesMu.
+func substringMatches(query string) []*pb.QueryResponse_Item {
+	entries := []*pb.QueryResponse_Item{}
+	q := strings.ToLower(query)
+
+	for k, v := range files {
+		if isHidden(v) {
+			continue
+		}
+
+		name := strings.ToLower(v.Name)
+
+		i := strings.Index(name, q)
+		if i < 0 {
+			continue
+		}
+
+		start := int32(utf8.RuneCountInString(name[:i]))
+		positions := make([]int32, utf8.RuneCountInString(q))
+
+		for j := range positions {
+			positions[j] = start + int32(j)
+		}
+
+		actions := []string{ActionStart}
+
+		if config.WindowIntegration {
+			actions = append(actions, ActionNewInstance)
+		}
+
+		entries = append(entries, &pb.QueryResponse_Item{
+			Identifier: k,
+			Text:       v.Name,
+			Type:       pb.QueryResponse_REGULAR,
+			Subtext:    v.GenericName,
+			Icon:       v.Icon,
+			Actions:    actions,
+			Provider:   Name,
+			Score:      1,
+			DedupKey:   common.ExecutableKey(v.Exec),
+			Fuzzyinfo: &pb.QueryResponse_Item_FuzzyInfo{
+				Start:     start,
+				Field:     "text",
+				Positions: positions,
+			},
+		})
+	}
+
+	return entries
+}
+
 func calcScore(q string, d *Data, exact bool) (string, int32, []int32, int32, bool) {
 	var scoreRes int32
 	var posRes []int32
@@ -300,6 +388,20 @@ func calcScore(q string, d *Data, exact bool) (string, int32, []int32, int32, bo
 		}
 	}
 
+	// keywords from overrides rank right below the name
+	if len(d.OverrideKeywords) > 0 {
+		kw := strings.Join(d.OverrideKeywords, ",")
+		score, pos, start := common.FuzzyScore(q, kw, exact)
+
+		if score > scoreRes {
+			scoreRes = score
+			posRes = pos
+			startRes = start
+			match = kw
+			modifier = 1
+		}
+	}
+
 	if scoreRes == 0 {
 		return "", 0, nil, 0, false
 	}
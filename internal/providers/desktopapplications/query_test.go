@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/common/history"
+)
+
+// setupQuery sets up two applications, an empty history and no recorded usage.
+func setupQuery(t *testing.T, frecency bool) {
+	t.Helper()
+
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	prevConfig, prevFiles, prevHistory := config, files, h
+
+	config = &Config{
+		Config:      common.Config{MinScore: 30},
+		ShowGeneric: true,
+		History:     true,
+		Frecency:    frecency,
+	}
+
+	files = map[string]*DesktopFile{
+		"fire.desktop":    {Data: Data{Name: "Fire", Exec: "fire"}},
+		"firefox.desktop": {Data: Data{Name: "Firefox", Exec: "firefox"}},
+	}
+
+	h = &history.History{
+		Provider: Name,
+		Data:     make(map[string]map[string]*history.HistoryData),
+	}
+
+	common.ResetUsage(Name)
+
+	t.Cleanup(func() {
+		config, files, h = prevConfig, prevFiles, prevHistory
+		common.ResetUsage(Name)
+	})
+}
+
+func scores(query string) map[string]int32 {
+	res := make(map[string]int32)
+
+	for _, v := range Query(context.Background(), nil, query, true, false, 0) {
+		res[v.Identifier] = v.Score
+	}
+
+	return res
+}
+
+func TestExactNameBeatsFrecentApp(t *testing.T) {
+	setupQuery(t, true)
+
+	plain := scores("fire")
+
+	for range 20 {
+		recordLaunch("firefox.desktop")
+	}
+
+	got := scores("fire")
+
+	if got["firefox.desktop"] <= plain["firefox.desktop"] || got["firefox.desktop"] <= plain["fire.desktop"] {
+		t.Fatalf("got %v, want firefox boosted above the unboosted fire (%v)", got, plain)
+	}
+
+	if got["fire.desktop"] <= got["firefox.desktop"] {
+		t.Errorf("got %v, want the application named like the query first", got)
+	}
+
+	if got := scores("firef"); got["firefox.desktop"] <= plain["firefox.desktop"] {
+		t.Errorf("got %v, frecency isn't applied to other queries", got)
+	}
+}
+
+func TestFrecencyReplacesHistory(t *testing.T) {
+	setupQuery(t, true)
+
+	plain := scores("fire")
+
+	h.Data["fire"] = map[string]*history.HistoryData{
+		"firefox.desktop": {LastUsed: time.Now(), Amount: 10},
+	}
+
+	if got := scores("fire"); got["firefox.desktop"] != plain["firefox.desktop"] {
+		t.Errorf("got %d, want %d: history was added on top of frecency", got["firefox.desktop"], plain["firefox.desktop"])
+	}
+
+	config.Frecency = false
+
+	if got := scores("fire"); got["firefox.desktop"] <= plain["firefox.desktop"] {
+		t.Errorf("got %d, want the history boost without frecency", got["firefox.desktop"])
+	}
+}
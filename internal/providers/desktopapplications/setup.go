@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"encoding/gob"
 	"fmt"
@@ -45,23 +46,29 @@ var readme string
 
 type Config struct {
 	common.Config                  `koanf:",squash"`
-	LaunchPrefix                   string            `koanf:"launch_prefix" desc:"overrides the default app2unit or uwsm prefix, if set." default:""`
-	Locale                         string            `koanf:"locale" desc:"to override systems locale" default:""`
-	ActionMinScore                 int               `koanf:"action_min_score" desc:"min score for actions to be shown" default:"20"`
-	ShowActions                    bool              `koanf:"show_actions" desc:"include application actions, f.e. 'New Private Window' for Firefox" default:"false"`
-	ShowGeneric                    bool              `koanf:"show_generic" desc:"include generic info when show_actions is true" default:"true"`
-	ShowActionsWithoutQuery        bool              `koanf:"show_actions_without_query" desc:"show application actions, if the search query is empty" default:"false"`
-	History                        bool              `koanf:"history" desc:"make use of history for sorting" default:"true"`
-	HistoryWhenEmpty               bool              `koanf:"history_when_empty" desc:"consider history when query is empty" default:"false"`
-	OnlySearchTitle                bool              `koanf:"only_search_title" desc:"ignore keywords, comments etc from desktop file when searching" default:"false"`
-	IconPlaceholder                string            `koanf:"icon_placeholder" desc:"placeholder icon for apps without icon" default:"applications-other"`
-	Aliases                        map[string]string `koanf:"aliases" desc:"setup aliases for applications. Matched aliases will always be placed on top of the list. Example: 'ffp' => '<identifier>'. Check elephant log output when activating an item to get its identifier." default:""`
-	Blacklist                      []string          `koanf:"blacklist" desc:"blacklist desktop files from being parsed. Regexp." default:"<empty>"`
-	WindowIntegration              bool              `koanf:"window_integration" desc:"will enable window integration, meaning focusing an open app instead of opening a new instance" default:"false"`
-	WindowIntegrationIgnoreActions bool              `koanf:"window_integration_ignore_actions" desc:"will ignore the window integration for actions" default:"true"`
-	WMIntegration                  bool              `koanf:"wm_integration" desc:"Moves apps to the workspace where they were launched at automatically. Currently Niri only." default:"false"`
-	ScoreOpenWindows               bool              `koanf:"score_open_windows" desc:"Apps that have open windows, get their score halved. Requires window_integration." default:"true"`
-	SingleInstanceApps             []string          `koanf:"single_instance_apps" desc:"application IDs that don't ever spawn a new window. " default:"[\"discord\"]"`
+	LaunchPrefix                   string              `koanf:"launch_prefix" desc:"overrides the default app2unit or uwsm prefix, if set." default:""`
+	Terminal                       string              `koanf:"terminal" desc:"terminal for applications with Terminal=true, f.e. 'foot', 'kitty --class launcher' or 'alacritty -e'. detected if empty" default:""`
+	Locale                         string              `koanf:"locale" desc:"to override systems locale" default:""`
+	ActionMinScore                 int                 `koanf:"action_min_score" desc:"min score for actions to be shown" default:"20"`
+	SubstringFallback              bool                `koanf:"substring_fallback" desc:"if nothing scores above min_score, show applications whose name contains the query" default:"true"`
+	ShowActions                    bool                `koanf:"show_actions" desc:"include application actions, f.e. 'New Private Window' for Firefox" default:"false"`
+	ShowGeneric                    bool                `koanf:"show_generic" desc:"include generic info when show_actions is true" default:"true"`
+	ShowActionsWithoutQuery        bool                `koanf:"show_actions_without_query" desc:"show application actions, if the search query is empty" default:"false"`
+	ActionsAsList                  bool                `koanf:"actions_as_list" desc:"list application actions as 'app_action:<action>' in the actions of the application, f.e. for a submenu. their names are in the item's metadata" default:"false"`
+	History                        bool                `koanf:"history" desc:"make use of history for sorting" default:"true"`
+	HistoryWhenEmpty               bool                `koanf:"history_when_empty" desc:"consider history when query is empty" default:"false"`
+	Frecency                       bool                `koanf:"frecency" desc:"boost applications by how often and how recently they were launched, also sorts the empty query. replaces the history boost" default:"false"`
+	Pinned                         []string            `koanf:"pinned" desc:"identifiers of applications pinned to the top, before the ones pinned via the pin action" default:"<empty>"`
+	OnlySearchTitle                bool                `koanf:"only_search_title" desc:"ignore keywords, comments etc from desktop file when searching" default:"false"`
+	IconPlaceholder                string              `koanf:"icon_placeholder" desc:"placeholder icon for apps without icon" default:"applications-other"`
+	Overrides                      map[string]Override `koanf:"overrides" desc:"change applications by their desktop-id, the file name without '.desktop', f.e. 'org.gnome.Nautilus'. Keys: name, icon, hide, exec and keywords" default:""`
+	Aliases                        map[string]string   `koanf:"aliases" desc:"setup aliases for applications. Matched aliases will always be placed on top of the list. Example: 'ffp' => '<identifier>'. Check elephant log output when activating an item to get its identifier." default:""`
+	Blacklist                      []string            `koanf:"blacklist" desc:"blacklist desktop files from being parsed. Regexp." default:"<empty>"`
+	WindowIntegration              bool                `koanf:"window_integration" desc:"will enable window integration, meaning focusing an open app instead of opening a new instance" default:"false"`
+	WindowIntegrationIgnoreActions bool                `koanf:"window_integration_ignore_actions" desc:"will ignore the window integration for actions" default:"true"`
+	WMIntegration                  bool                `koanf:"wm_integration" desc:"Moves apps to the workspace where they were launched at automatically. Currently Niri only." default:"false"`
+	ScoreOpenWindows               bool                `koanf:"score_open_windows" desc:"Apps that have open windows, get their score halved. Requires window_integration." default:"true"`
+	SingleInstanceApps             []string            `koanf:"single_instance_apps" desc:"application IDs that don't ever spawn a new window. " default:"[\"discord\"]"`
 }
 
 func loadpinned() []string {
@@ -95,6 +102,7 @@ func Setup() {
 		},
 		ScoreOpenWindows:        true,
 		ActionMinScore:          20,
+		SubstringFallback:       true,
 		OnlySearchTitle:         false,
 		ShowActions:             false,
 		ShowGeneric:             true,
@@ -102,8 +110,10 @@ func Setup() {
 		History:                 true,
 		WMIntegration:           false,
 		HistoryWhenEmpty:        false,
+		Frecency:                false,
 		IconPlaceholder:         "applications-other",
 		Aliases:                 map[string]string{},
+		Overrides:               map[string]Override{},
 		WindowIntegration:       false,
 		SingleInstanceApps:      []string{"discord"},
 	}
@@ -135,6 +145,13 @@ func Setup() {
 	slog.Info(Name, "desktop files", len(files), "time", time.Since(start))
 }
 
+// Stop closes the watcher.
+func Stop(_ context.Context) {
+	if watcher != nil {
+		watcher.Close()
+	}
+}
+
 func Available() bool {
 	return true
 }
@@ -159,5 +176,7 @@ func HideFromProviderlist() bool {
 }
 
 func State(provider string) *pb.ProviderStateResponse {
-	return &pb.ProviderStateResponse{}
+	return &pb.ProviderStateResponse{
+		Actions: []string{ActionResetHistory},
+	}
 }
@@ -0,0 +1,41 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	disabled   = make(map[string]bool)
+	disabledMu sync.RWMutex
+)
+
+// SetEnabled enables or disables a provider at runtime. Disabled providers
+// aren't queried and can't be activated until they are enabled again.
+func SetEnabled(provider string, enabled bool) error {
+	if _, ok := Providers[provider]; !ok {
+		return fmt.Errorf("unknown provider: %s", provider)
+	}
+
+	disabledMu.Lock()
+	defer disabledMu.Unlock()
+
+	if enabled {
+		delete(disabled, provider)
+	} else {
+		disabled[provider] = true
+	}
+
+	return nil
+}
+
+// Enabled reports if a provider is enabled. "menus:<menu>" resolves to "menus".
+func Enabled(provider string) bool {
+	provider, _, _ = strings.Cut(provider, ":")
+
+	disabledMu.RLock()
+	defer disabledMu.RUnlock()
+
+	return !disabled[provider]
+}
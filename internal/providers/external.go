@@ -0,0 +1,385 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// restart backoff, shortened by tests
+var (
+	externalMinBackoff = time.Second
+	externalMaxBackoff = time.Minute
+	// a plugin running this long is considered healthy again, resetting the backoff
+	externalStable = time.Minute
+)
+
+const (
+	externalRequestTimeout = 5 * time.Second
+	externalMinScore       = 20
+	externalMaxLine        = 16 * 1024 * 1024
+)
+
+// OnExternalUpdate is called when an external plugin reports changed items, f.e. to notify subscribers.
+var OnExternalUpdate func(provider string)
+
+type externalRequest struct {
+	ID         uint64 `json:"id"`
+	Verb       string `json:"verb"`
+	Query      string `json:"query,omitempty"`
+	Exact      bool   `json:"exact,omitempty"`
+	Identifier string `json:"identifier,omitempty"`
+	Action     string `json:"action,omitempty"`
+	Arguments  string `json:"arguments,omitempty"`
+}
+
+type externalItem struct {
+	Identifier string   `json:"identifier"`
+	Text       string   `json:"text"`
+	Subtext    string   `json:"subtext"`
+	Icon       string   `json:"icon"`
+	Actions    []string `json:"actions"`
+	Score      int32    `json:"score"`
+}
+
+type externalResponse struct {
+	ID      uint64         `json:"id"`
+	Updated bool           `json:"updated"`
+	Error   string         `json:"error"`
+	Message string         `json:"message"`
+	Items   []externalItem `json:"items"`
+	States  []string       `json:"states"`
+	Actions []string       `json:"actions"`
+}
+
+// external wraps a long running plugin process, which answers requests with json lines.
+type external struct {
+	cfg     common.ExternalPlugin
+	mut     sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	pending map[uint64]chan externalResponse
+	nextID  uint64
+	stopped bool
+}
+
+func newExternal(cfg common.ExternalPlugin) Provider {
+	e := &external{
+		cfg:     cfg,
+		pending: make(map[uint64]chan externalResponse),
+	}
+
+	name := cfg.Name
+	namePretty := cfg.NamePretty
+
+	if namePretty == "" {
+		namePretty = name
+	}
+
+	return Provider{
+		Name:       &name,
+		NamePretty: &namePretty,
+		Available:  func() bool { return true },
+		PrintDoc: func() {
+			fmt.Printf("### %s\n\nExternal plugin, started with `%s`.\n", namePretty, cfg.Command)
+		},
+		Setup:                func() { go e.run() },
+		Stop:                 e.stop,
+		HideFromProviderlist: func() bool { return false },
+		Icon:                 func() string { return cfg.Icon },
+		State:                e.state,
+		Activate:             e.activate,
+		Query:                e.query,
+	}
+}
+
+// run keeps the plugin running, restarting it with a backoff if it exits.
+func (e *external) run() {
+	minBackoff, maxBackoff, stable := externalMinBackoff, externalMaxBackoff, externalStable
+	backoff := minBackoff
+
+	for {
+		start := time.Now()
+		err := e.serve()
+
+		e.mut.Lock()
+		stopped := e.stopped
+		e.stdin = nil
+
+		for id, ch := range e.pending {
+			close(ch)
+			delete(e.pending, id)
+		}
+
+		e.mut.Unlock()
+
+		if stopped {
+			return
+		}
+
+		if time.Since(start) > stable {
+			backoff = minBackoff
+		}
+
+		slog.Error(e.cfg.Name, "exited", err, "restart", backoff)
+
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+
+		e.mut.Lock()
+		stopped = e.stopped
+		e.mut.Unlock()
+
+		if stopped {
+			return
+		}
+	}
+}
+
+func (e *external) serve() error {
+	cmd := exec.Command("sh", "-c", e.cfg.Command)
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	e.mut.Lock()
+	e.cmd = cmd
+	e.stdin = stdin
+	e.mut.Unlock()
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), externalMaxLine)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		if line == "updated" {
+			e.updated()
+			continue
+		}
+
+		var res externalResponse
+
+		if err := json.Unmarshal([]byte(line), &res); err != nil {
+			slog.Error(e.cfg.Name, "decode", err)
+			continue
+		}
+
+		if res.Updated {
+			e.updated()
+			continue
+		}
+
+		e.mut.Lock()
+		ch, ok := e.pending[res.ID]
+		delete(e.pending, res.ID)
+		e.mut.Unlock()
+
+		if ok {
+			ch <- res
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		slog.Error(e.cfg.Name, "read", err)
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	return cmd.Wait()
+}
+
+func (e *external) updated() {
+	if OnExternalUpdate != nil {
+		OnExternalUpdate(e.cfg.Name)
+	}
+}
+
+func (e *external) stop(ctx context.Context) {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	e.stopped = true
+
+	if e.cmd != nil && e.cmd.Process != nil {
+		syscall.Kill(-e.cmd.Process.Pid, syscall.SIGTERM)
+	}
+}
+
+func (e *external) request(ctx context.Context, req externalRequest) (externalResponse, error) {
+	e.mut.Lock()
+
+	if e.stdin == nil {
+		e.mut.Unlock()
+		return externalResponse{}, errors.New("plugin not running")
+	}
+
+	e.nextID++
+	req.ID = e.nextID
+
+	ch := make(chan externalResponse, 1)
+	e.pending[req.ID] = ch
+
+	b, err := json.Marshal(req)
+	if err == nil {
+		_, err = e.stdin.Write(append(b, '\n'))
+	}
+
+	if err != nil {
+		delete(e.pending, req.ID)
+	}
+
+	e.mut.Unlock()
+
+	if err != nil {
+		return externalResponse{}, err
+	}
+
+	select {
+	case res, ok := <-ch:
+		if !ok {
+			return externalResponse{}, errors.New("plugin exited")
+		}
+
+		if res.Error != "" {
+			return res, errors.New(res.Error)
+		}
+
+		return res, nil
+	case <-ctx.Done():
+		e.mut.Lock()
+		delete(e.pending, req.ID)
+		e.mut.Unlock()
+
+		return externalResponse{}, ctx.Err()
+	}
+}
+
+func (e *external) query(ctx context.Context, conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item {
+	entries := []*pb.QueryResponse_Item{}
+
+	res, err := e.request(ctx, externalRequest{
+		Verb:  "query",
+		Query: query,
+		Exact: exact,
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error(e.cfg.Name, "query", err)
+		}
+
+		return entries
+	}
+
+	for k, v := range res.Items {
+		item := &pb.QueryResponse_Item{
+			Identifier: v.Identifier,
+			Text:       v.Text,
+			Subtext:    v.Subtext,
+			Icon:       v.Icon,
+			Actions:    v.Actions,
+			Provider:   e.cfg.Name,
+			Score:      v.Score,
+			Type:       pb.QueryResponse_REGULAR,
+		}
+
+		if item.Icon == "" {
+			item.Icon = e.cfg.Icon
+		}
+
+		// plugins not scoring their items get them fuzzy matched, or keep their order for empty queries
+		if item.Score == 0 {
+			if query == "" {
+				item.Score = int32(len(res.Items) - k)
+			} else {
+				item.Fuzzyinfo = &pb.QueryResponse_Item_FuzzyInfo{
+					Field: "text",
+				}
+
+				item.Score, item.Fuzzyinfo.Positions, item.Fuzzyinfo.Start = common.FuzzyScore(query, item.Text, exact)
+
+				if item.Score <= externalMinScore {
+					continue
+				}
+			}
+		}
+
+		entries = append(entries, item)
+	}
+
+	return entries
+}
+
+func (e *external) activate(single bool, identifier, action, query, args string, format uint8, conn net.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), externalRequestTimeout)
+	defer cancel()
+
+	res, err := e.request(ctx, externalRequest{
+		Verb:       "activate",
+		Identifier: identifier,
+		Action:     action,
+		Query:      query,
+		Arguments:  args,
+	})
+	if err != nil {
+		slog.Error(e.cfg.Name, "activate", err)
+		common.ReportActivationError(conn, err)
+
+		return
+	}
+
+	if res.Message != "" {
+		common.ReportActivation(conn, common.ActivationResult{
+			Status:  common.ActivationOk,
+			Message: res.Message,
+		})
+	}
+}
+
+func (e *external) state(provider string) *pb.ProviderStateResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), externalRequestTimeout)
+	defer cancel()
+
+	res, err := e.request(ctx, externalRequest{
+		Verb: "state",
+	})
+	if err != nil {
+		slog.Error(e.cfg.Name, "state", err)
+		return &pb.ProviderStateResponse{}
+	}
+
+	return &pb.ProviderStateResponse{
+		States:  res.States,
+		Actions: res.Actions,
+	}
+}
@@ -0,0 +1,175 @@
+package providers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+// fakePlugin records every start in the returned file and answers queries
+// with one item, after failing the given amount of starts.
+const fakePlugin = `#!/bin/sh
+date +%s%N >> "$STARTS"
+
+if [ "$(wc -l < "$STARTS")" -le "$FAILURES" ]; then
+	exit 1
+fi
+
+while read -r line; do
+	id=$(echo "$line" | sed 's/.*"id":\([0-9]*\).*/\1/')
+	echo "{\"id\":$id,\"items\":[{\"identifier\":\"item\",\"text\":\"item\",\"score\":100}]}"
+done
+`
+
+func shortBackoff(t *testing.T, minimum, maximum time.Duration) {
+	t.Helper()
+
+	prevMin, prevMax := externalMinBackoff, externalMaxBackoff
+	externalMinBackoff, externalMaxBackoff = minimum, maximum
+
+	t.Cleanup(func() {
+		externalMinBackoff, externalMaxBackoff = prevMin, prevMax
+	})
+}
+
+func startFakePlugin(t *testing.T, failures int) (Provider, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "plugin")
+	starts := filepath.Join(dir, "starts")
+
+	if err := os.WriteFile(script, []byte(fakePlugin), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	p := newExternal(common.ExternalPlugin{
+		Name:    "fake",
+		Command: "STARTS=" + starts + " FAILURES=" + strconv.Itoa(failures) + " " + script,
+	})
+
+	p.Setup()
+
+	t.Cleanup(func() {
+		p.Stop(context.Background())
+	})
+
+	return p, starts
+}
+
+// startTimes returns when the plugin was started.
+func startTimes(t *testing.T, file string) []time.Time {
+	t.Helper()
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	res := []time.Time{}
+
+	for v := range strings.FieldsSeq(string(b)) {
+		ns, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res = append(res, time.Unix(0, ns))
+	}
+
+	return res
+}
+
+func TestExternalQuery(t *testing.T) {
+	p, _ := startFakePlugin(t, 0)
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		if res := p.Query(context.Background(), nil, "x", true, false, 0); len(res) == 1 {
+			if res[0].Identifier != "item" || res[0].Provider != "fake" || res[0].Score != 100 {
+				t.Errorf("got %v", res[0])
+			}
+
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("plugin didn't answer")
+}
+
+func TestExternalRestartBackoff(t *testing.T) {
+	shortBackoff(t, 50*time.Millisecond, time.Second)
+
+	p, starts := startFakePlugin(t, 3)
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for len(p.Query(context.Background(), nil, "x", true, false, 0)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("plugin wasn't restarted")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	times := startTimes(t, starts)
+
+	if len(times) != 4 {
+		t.Fatalf("got %d starts, want 4", len(times))
+	}
+
+	// 50ms, 100ms and 200ms between the starts
+	for i := 1; i < len(times); i++ {
+		want := externalMinBackoff << (i - 1)
+
+		if gap := times[i].Sub(times[i-1]); gap < want {
+			t.Errorf("restart %d after %s, want at least %s", i, gap, want)
+		}
+	}
+}
+
+func TestExternalMaxBackoff(t *testing.T) {
+	shortBackoff(t, 20*time.Millisecond, 40*time.Millisecond)
+
+	_, starts := startFakePlugin(t, 1000)
+
+	time.Sleep(500 * time.Millisecond)
+
+	// without the limit it'd be 20, 40, 80 and 160ms, so at most 5 starts
+	if n := len(startTimes(t, starts)); n < 7 {
+		t.Errorf("got %d starts, backoff exceeded its maximum", n)
+	}
+}
+
+func TestExternalStopDuringBackoff(t *testing.T) {
+	shortBackoff(t, 200*time.Millisecond, time.Second)
+
+	p, starts := startFakePlugin(t, 1000)
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for len(startTimes(t, starts)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("plugin wasn't started")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	p.Stop(context.Background())
+
+	time.Sleep(500 * time.Millisecond)
+
+	if n := len(startTimes(t, starts)); n != 1 {
+		t.Errorf("got %d starts, plugin was restarted after stopping", n)
+	}
+}
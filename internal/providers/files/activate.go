@@ -4,10 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
-	"os/exec"
 	"path/filepath"
-	"strings"
-	"syscall"
 
 	"github.com/abenz1267/elephant/v2/pkg/common"
 )
@@ -36,66 +33,36 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 	switch action {
 	case ActionLocalsend:
-		cmd := exec.Command("sh", "-c", strings.TrimSpace(fmt.Sprintf("%s %s %s", common.LaunchPrefix(""), "localsend", path)))
-
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			Setsid: true,
-		}
-
-		err := cmd.Start()
+		_, err := common.RunDetached(common.RunOpts{
+			Command: fmt.Sprintf("localsend %s", path),
+		})
 		if err != nil {
 			slog.Error(Name, "actionlocalsend", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+			common.ReportActivationError(conn, err)
 		}
 	case ActionOpen, ActionOpenDir:
 		if action == ActionOpenDir {
 			path = filepath.Dir(path)
 		}
 
-		run := strings.TrimSpace(fmt.Sprintf("%s xdg-open '%s'", common.LaunchPrefix(config.LaunchPrefix), path))
-
-		if common.ForceTerminalForFile(path) {
-			run = common.WrapWithTerminal(run)
-		}
-
-		cmd := exec.Command("sh", "-c", run)
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			Setsid: true,
-		}
-
-		err := cmd.Start()
+		_, err := common.RunDetached(common.RunOpts{
+			Command:  fmt.Sprintf("xdg-open '%s'", path),
+			Prefix:   config.LaunchPrefix,
+			Terminal: common.ForceTerminalForFile(path),
+		})
 		if err != nil {
 			slog.Error(Name, "actionopen", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+			common.ReportActivationError(conn, err)
 		}
 	case ActionCopyPath:
-		cmd := exec.Command("wl-copy", path)
-
-		err := cmd.Start()
-		if err != nil {
+		if err := common.CopyToClipboard([]byte(path), "text/plain"); err != nil {
 			slog.Error(Name, "actioncopypath", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+			common.ReportActivationError(conn, err)
 		}
-
 	case ActionCopyFile:
-		cmd := exec.Command("wl-copy", "-t", "text/uri-list", fmt.Sprintf("file://%s", path))
-
-		err := cmd.Start()
-		if err != nil {
+		if err := common.CopyToClipboard(fmt.Appendf(nil, "file://%s", path), "text/uri-list"); err != nil {
 			slog.Error(Name, "actioncopyfile", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+			common.ReportActivationError(conn, err)
 		}
 	default:
 		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
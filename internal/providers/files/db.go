@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log/slog"
 	"os"
@@ -104,7 +105,7 @@ func getFile(identifier string) *File {
 	return &f
 }
 
-func getFilesByQuery(query string, _ bool) []File {
+func getFilesByQuery(ctx context.Context, query string, _ bool) []File {
 	var result []File
 
 	path := common.CacheFile("files.db")
@@ -117,11 +118,13 @@ func getFilesByQuery(query string, _ bool) []File {
 
 	var rows *sql.Rows
 
-	if query != "" {
-		likePattern := "%" + query + "%"
-		rows, err = queryDB.Query("SELECT identifier, path, changed FROM files WHERE path LIKE ? ORDER BY changed DESC LIMIT 1000", likePattern)
+	q, _ := common.ParsePathMatchMode(query)
+
+	if q != "" {
+		likePattern := "%" + q + "%"
+		rows, err = queryDB.QueryContext(ctx, "SELECT identifier, path, changed FROM files WHERE path LIKE ? ORDER BY changed DESC LIMIT 1000", likePattern)
 	} else {
-		rows, err = queryDB.Query("SELECT identifier, path, changed FROM files WHERE path NOT LIKE '%/' ORDER BY changed DESC LIMIT 100")
+		rows, err = queryDB.QueryContext(ctx, "SELECT identifier, path, changed FROM files WHERE path NOT LIKE '%/' ORDER BY changed DESC LIMIT 100")
 	}
 
 	if err != nil {
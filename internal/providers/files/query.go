@@ -1,28 +1,39 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"net"
 	"strings"
 	"time"
 
+	"al.essio.dev/pkg/shellescape"
 	"github.com/abenz1267/elephant/v2/internal/util"
 	"github.com/abenz1267/elephant/v2/pkg/common"
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
 )
 
-func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func Query(ctx context.Context, conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	start := time.Now()
 
 	entries := []*pb.QueryResponse_Item{}
 	actions := []string{ActionOpen, ActionOpenDir, ActionCopyFile, ActionCopyPath}
 
-	results := getFilesByQuery(query, exact)
+	results := getFilesByQuery(ctx, query, exact)
 
 	for k, v := range results {
+		if ctx.Err() != nil {
+			return nil
+		}
+
 		p := v.Path
 		pt := util.PreviewTypeFile
 
+		if config.PreviewCommand != "" {
+			p = ""
+			pt = util.PreviewTypeLazy
+		}
+
 		for _, i := range config.IgnorePreviews {
 			if strings.HasPrefix(v.Path, i.Path) {
 				p = i.Placeholder
@@ -48,7 +59,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 		}
 
 		if query != "" {
-			score, pos, start := common.FuzzyScore(query, v.Path, exact)
+			score, pos, start := common.FuzzyScorePath(query, v.Path, exact)
 			entry.Score = score
 			entry.Fuzzyinfo = &pb.QueryResponse_Item_FuzzyInfo{
 				Start:     start,
@@ -64,3 +75,12 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 
 	return entries
 }
+
+func Preview(identifier string) (string, string) {
+	f := getFile(identifier)
+	if f == nil || config.PreviewCommand == "" {
+		return "", ""
+	}
+
+	return strings.ReplaceAll(config.PreviewCommand, "%FILE%", shellescape.Quote(f.Path)), util.PreviewTypeCommand
+}
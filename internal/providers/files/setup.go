@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"crypto/md5"
 	_ "embed"
 	"encoding/hex"
@@ -35,7 +36,7 @@ var (
 )
 
 type IgnoredPreview struct {
-	Path        string `koanf:"path" desc:"path to ignore preview for" default:""`
+	Path        string `koanf:"path" desc:"path to ignore preview for" default:"" expand:"true"`
 	Placeholder string `koanf:"placeholder" desc:"text to display instead" default:""`
 }
 
@@ -44,10 +45,11 @@ type Config struct {
 	LaunchPrefix   string           `koanf:"launch_prefix" desc:"overrides the default app2unit or uwsm prefix, if set." default:""`
 	IgnoredDirs    []string         `koanf:"ignored_dirs" desc:"ignore these directories. regexp based." default:""`
 	IgnorePreviews []IgnoredPreview `koanf:"ignore_previews" desc:"paths will not have a preview" default:""`
-	IgnoreWatching []string         `koanf:"ignore_watching" desc:"paths will not be watched" default:""`
-	SearchDirs     []string         `koanf:"search_dirs" desc:"directories to search for files" default:"$HOME"`
+	IgnoreWatching []string         `koanf:"ignore_watching" desc:"paths will not be watched" default:"" expand:"true"`
+	SearchDirs     []string         `koanf:"search_dirs" desc:"directories to search for files" default:"$HOME" expand:"true"`
 	FdFlags        []string         `koanf:"fd_flags" desc:"flags for fd" default:"['--ignore-vcs', '--type,' ,'file', '--type,' 'directory']"`
 	WatchBuffer    int              `koanf:"watch_buffer" desc:"time in millisecnds elephant will gather changed paths before processing them" default:"2000"`
+	PreviewCommand string           `koanf:"preview_command" desc:"command generating previews, run by elephant when a preview is requested. use '%FILE%' as placeholder for the path. frontends need lazy preview support." default:""`
 }
 
 func Setup() {
@@ -66,8 +68,9 @@ func Setup() {
 
 	config = &Config{
 		Config: common.Config{
-			Icon:     "folder",
-			MinScore: 20,
+			Icon:           "folder",
+			MinScore:       20,
+			MinQueryLength: 2,
 		},
 		LaunchPrefix: "",
 		SearchDirs:   []string{},
@@ -237,6 +240,19 @@ func Setup() {
 	slog.Info(Name, "time", time.Since(start))
 }
 
+// Stop closes the watcher and the database.
+func Stop(_ context.Context) {
+	if watcher != nil {
+		watcher.Close()
+	}
+
+	if db != nil {
+		if err := db.Close(); err != nil {
+			slog.Error(Name, "stop", err)
+		}
+	}
+}
+
 func Available() bool {
 	p, err := exec.LookPath("fd")
 
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/gob"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/internal/util"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+var (
+	Name       = "history"
+	NamePretty = "History"
+	config     *Config
+	file       = common.StateFile("activation_history.gob")
+	mu         sync.Mutex
+	entries    []*Entry
+	nextID     uint64
+)
+
+//go:embed README.md
+var readme string
+
+const (
+	ActionActivate = "activate"
+	ActionRemove   = "remove"
+	ActionClear    = "clear_history"
+)
+
+type Config struct {
+	common.Config     `koanf:",squash"`
+	MaxItems          int      `koanf:"max_items" desc:"max amount of remembered activations" default:"100"`
+	ExcludedProviders []string `koanf:"excluded_providers" desc:"activations of these providers are not recorded" default:"clipboard"`
+}
+
+// Entry is a recorded activation. Activating the same item with the same action again only updates it.
+type Entry struct {
+	ID         uint64
+	Provider   string
+	Identifier string
+	Action     string
+	Query      string
+	Arguments  string
+	Text       string
+	Subtext    string
+	Icon       string
+	Time       time.Time
+}
+
+func Setup() {
+	config = &Config{
+		Config: common.Config{
+			Icon:     "document-open-recent",
+			MinScore: 30,
+		},
+		MaxItems:          100,
+		ExcludedProviders: []string{"clipboard"},
+	}
+
+	common.LoadConfig(Name, config)
+
+	if config.NamePretty != "" {
+		NamePretty = config.NamePretty
+	}
+
+	loadFromFile()
+
+	common.OnActivation(record)
+}
+
+func Available() bool {
+	return true
+}
+
+func PrintDoc() {
+	fmt.Println(readme)
+	fmt.Println()
+	util.PrintConfig(Config{}, Name)
+}
+
+func record(a common.Activation) {
+	if a.Provider == Name || a.Action == common.ActionResetUsage || slices.Contains(config.ExcludedProviders, strings.Split(a.Provider, ":")[0]) {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	e := &Entry{
+		Provider:   a.Provider,
+		Identifier: a.Identifier,
+		Action:     a.Action,
+	}
+
+	if i := slices.IndexFunc(entries, func(v *Entry) bool {
+		return v.Provider == a.Provider && v.Identifier == a.Identifier && v.Action == a.Action
+	}); i != -1 {
+		e = entries[i]
+		entries = slices.Delete(entries, i, i+1)
+	} else {
+		nextID++
+		e.ID = nextID
+	}
+
+	e.Query = a.Query
+	e.Arguments = a.Arguments
+	e.Time = time.Now()
+
+	// activations via the cli don't know the item, so the last known one is kept
+	if a.Text != "" {
+		e.Text = a.Text
+		e.Subtext = a.Subtext
+		e.Icon = a.Icon
+	}
+
+	entries = slices.Insert(entries, 0, e)
+
+	saveToFile()
+}
+
+func find(identifier string) (int, bool) {
+	id, err := strconv.ParseUint(identifier, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	i := slices.IndexFunc(entries, func(e *Entry) bool {
+		return e.ID == id
+	})
+
+	return i, i != -1
+}
+
+func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	mu.Lock()
+
+	switch action {
+	case ActionClear:
+		entries = []*Entry{}
+		saveToFile()
+		mu.Unlock()
+
+		return
+	case ActionRemove:
+		if i, ok := find(identifier); ok {
+			entries = slices.Delete(entries, i, i+1)
+			saveToFile()
+		}
+
+		mu.Unlock()
+
+		return
+	}
+
+	i, ok := find(identifier)
+	if !ok {
+		mu.Unlock()
+		common.ReportActivationError(conn, fmt.Errorf("unknown history item: %s", identifier))
+
+		return
+	}
+
+	e := entries[i]
+	e.Time = time.Now()
+	entries = slices.Insert(slices.Delete(entries, i, i+1), 0, e)
+	saveToFile()
+
+	mu.Unlock()
+
+	provider := strings.Split(e.Provider, ":")[0]
+
+	p, ok := providers.Providers[provider]
+	if !ok || !providers.Enabled(provider) {
+		common.ReportActivationError(conn, fmt.Errorf("provider not available: %s", e.Provider))
+		return
+	}
+
+	p.Activate(single, e.Identifier, e.Action, e.Query, e.Arguments, format, conn)
+}
+
+func Query(_ context.Context, conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+	start := time.Now()
+	res := []*pb.QueryResponse_Item{}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for k, v := range entries {
+		e := &pb.QueryResponse_Item{
+			Identifier: strconv.FormatUint(v.ID, 10),
+			Text:       v.Text,
+			Subtext:    v.Subtext,
+			Icon:       v.Icon,
+			Provider:   Name,
+			Actions:    []string{ActionActivate, ActionRemove},
+			Type:       pb.QueryResponse_REGULAR,
+		}
+
+		if e.Text == "" {
+			e.Text = v.Identifier
+		}
+
+		if e.Subtext == "" {
+			if p, ok := providers.Providers[strings.Split(v.Provider, ":")[0]]; ok {
+				e.Subtext = *p.NamePretty
+			}
+		}
+
+		if query == "" {
+			e.Score = 1_000_000 - int32(k)
+			res = append(res, e)
+
+			continue
+		}
+
+		e.Fuzzyinfo = &pb.QueryResponse_Item_FuzzyInfo{
+			Field: "text",
+		}
+
+		e.Score, e.Fuzzyinfo.Positions, e.Fuzzyinfo.Start = common.FuzzyScore(query, e.Text, exact)
+
+		if e.Score > config.MinScoreFor(query) {
+			res = append(res, e)
+		}
+	}
+
+	slog.Debug(Name, "query", time.Since(start))
+
+	return res
+}
+
+func Icon() string {
+	return config.Icon
+}
+
+func HideFromProviderlist() bool {
+	return config.HideFromProviderlist
+}
+
+func State(provider string) *pb.ProviderStateResponse {
+	mu.Lock()
+	defer mu.Unlock()
+
+	actions := []string{}
+
+	if len(entries) > 0 {
+		actions = append(actions, ActionClear)
+	}
+
+	return &pb.ProviderStateResponse{
+		Actions: actions,
+	}
+}
+
+func Dump() map[string]any {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return map[string]any{
+		"items":  len(entries),
+		"config": config,
+	}
+}
+
+func loadFromFile() {
+	if !common.FileExists(file) {
+		return
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		slog.Error(Name, "load", err)
+		return
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&entries); err != nil {
+		slog.Error(Name, "decoding", err)
+		return
+	}
+
+	for _, v := range entries {
+		nextID = max(nextID, v.ID)
+	}
+}
+
+func saveToFile() {
+	if len(entries) > config.MaxItems {
+		entries = entries[:config.MaxItems]
+	}
+
+	var b bytes.Buffer
+
+	if err := gob.NewEncoder(&b).Encode(entries); err != nil {
+		slog.Error(Name, "encode", err)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		slog.Error(Name, "createdirs", err)
+		return
+	}
+
+	// write to a temp file first, so an interrupted save doesn't leave a broken history
+	tmp := file + ".tmp"
+
+	if err := os.WriteFile(tmp, b.Bytes(), 0o600); err != nil {
+		slog.Error(Name, "writefile", err)
+		return
+	}
+
+	if err := os.Rename(tmp, file); err != nil {
+		slog.Error(Name, "writefile", err)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+// setupHistory sets up an empty history saved to a temporary file.
+func setupHistory(t *testing.T) {
+	t.Helper()
+
+	prevConfig, prevFile := config, file
+
+	config = &Config{
+		Config:            common.Config{MinScore: 30},
+		MaxItems:          100,
+		ExcludedProviders: []string{"clipboard", "menus"},
+	}
+
+	file = filepath.Join(t.TempDir(), "activation_history.gob")
+	entries, nextID = nil, 0
+
+	t.Cleanup(func() {
+		config, file = prevConfig, prevFile
+		entries, nextID = nil, 0
+	})
+}
+
+func recorded() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	res := []string{}
+
+	for _, v := range entries {
+		res = append(res, v.Provider+"/"+v.Identifier+"/"+v.Action)
+	}
+
+	return res
+}
+
+func TestRecordExclusions(t *testing.T) {
+	setupHistory(t)
+
+	for _, a := range []common.Activation{
+		{Provider: "desktopapplications", Identifier: "firefox.desktop", Action: "start", Text: "Firefox"},
+		{Provider: "clipboard", Identifier: "abc", Action: "copy"},
+		{Provider: "menus:power", Identifier: "reboot", Action: "activate"},
+		{Provider: Name, Identifier: "1", Action: ActionActivate},
+		{Provider: "snippets", Action: common.ActionResetUsage},
+		{Provider: "runner", Identifier: "htop", Action: "run"},
+		{Provider: "desktopapplications", Identifier: "firefox.desktop", Action: "start"},
+	} {
+		record(a)
+	}
+
+	want := []string{"desktopapplications/firefox.desktop/start", "runner/htop/run"}
+
+	if got := recorded(); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// activating again moves the entry to the front, without losing its text
+	if e := entries[0]; e.ID != 1 || e.Text != "Firefox" {
+		t.Errorf("got id %d and text %q, want the existing entry updated", e.ID, e.Text)
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	setupHistory(t)
+
+	config.MaxItems = 2
+
+	for _, v := range []string{"a", "b", "c"} {
+		record(common.Activation{Provider: "runner", Identifier: v, Action: "run", Text: v})
+	}
+
+	saved := recorded()
+
+	entries, nextID = nil, 0
+	loadFromFile()
+
+	if got := recorded(); !slices.Equal(got, saved) || len(got) != 2 {
+		t.Errorf("got %v, want %v limited to max_items", got, saved)
+	}
+
+	if nextID != 3 {
+		t.Errorf("got next id %d, want 3 so new entries don't reuse ids", nextID)
+	}
+
+	if _, err := os.Stat(file + ".tmp"); err == nil {
+		t.Error("temporary file was left behind")
+	}
+}
+
+func TestClearAndRemove(t *testing.T) {
+	setupHistory(t)
+
+	for _, v := range []string{"a", "b"} {
+		record(common.Activation{Provider: "runner", Identifier: v, Action: "run"})
+	}
+
+	Activate(false, strconv.FormatUint(entries[0].ID, 10), ActionRemove, "", "", 0, nil)
+
+	if got := recorded(); !slices.Equal(got, []string{"runner/a/run"}) {
+		t.Errorf("got %v after removing b", got)
+	}
+
+	if State(Name).Actions[0] != ActionClear {
+		t.Error("clear action not offered")
+	}
+
+	Activate(false, "", ActionClear, "", "", 0, nil)
+	loadFromFile()
+
+	if got := recorded(); len(got) != 0 {
+		t.Errorf("got %v after clearing", got)
+	}
+
+	if len(State(Name).Actions) != 0 {
+		t.Error("clear action offered for an empty history")
+	}
+}
+
+func TestActivateRedispatches(t *testing.T) {
+	setupHistory(t)
+
+	type activation struct {
+		identifier, action, query, args string
+	}
+
+	got := make(chan activation, 1)
+
+	if providers.Providers == nil {
+		providers.Providers = make(map[string]providers.Provider)
+	}
+
+	pretty := "Menus"
+
+	providers.Providers["menus"] = providers.Provider{
+		NamePretty: &pretty,
+		Activate: func(_ bool, identifier, action, query, args string, _ uint8, _ net.Conn) {
+			got <- activation{identifier, action, query, args}
+		},
+	}
+
+	t.Cleanup(func() {
+		delete(providers.Providers, "menus")
+	})
+
+	config.ExcludedProviders = nil
+
+	record(common.Activation{Provider: "menus:power", Identifier: "reboot", Action: "activate", Query: "reb", Arguments: "now"})
+	record(common.Activation{Provider: "runner", Identifier: "htop", Action: "run"})
+
+	Activate(false, "1", ActionActivate, "", "", 0, nil)
+
+	if a := <-got; a != (activation{"reboot", "activate", "reb", "now"}) {
+		t.Errorf("got %+v, want the recorded activation", a)
+	}
+
+	if got := recorded(); got[0] != "menus:power/reboot/activate" {
+		t.Errorf("got %v, want the activated entry first", got)
+	}
+
+	res := Query(context.Background(), nil, "", true, false, 0)
+
+	if len(res) != 2 || res[0].Identifier != "1" || res[0].Subtext != pretty {
+		t.Errorf("got %v, want both entries, most recent first", res)
+	}
+}
@@ -2,6 +2,7 @@
 package providers
 
 import (
+	"context"
 	"io/fs"
 	"log/slog"
 	"net"
@@ -29,17 +30,27 @@ type Provider struct {
 	NamePretty           *string
 	State                func(string) *pb.ProviderStateResponse
 	Setup                func()
+	Reload               func()
+	Preview              func(identifier string) (string, string)
+	Dump                 func() map[string]any
+	Stop                 func(ctx context.Context)
 	HideFromProviderlist func() bool
 	Icon                 func() string
 	Activate             func(single bool, identifier, action, query, args string, format uint8, conn net.Conn)
-	Query                func(conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item
+	Query                func(ctx context.Context, conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item
 }
 
 var (
 	Providers      map[string]Provider
 	QueryProviders map[uint32][]string
+	setupWG        sync.WaitGroup
 )
 
+// WaitSetup blocks until the Setup of all loaded providers returned.
+func WaitSetup() {
+	setupWG.Wait()
+}
+
 func Load(setup bool) {
 	common.LoadMenus()
 	ignored := common.GetElephantConfig().IgnoredProviders
@@ -141,12 +152,50 @@ func Load(setup bool) {
 					slog.Error("providers", "load", err, "provider", path)
 				}
 
+				var reloadFunc func()
+
+				// optional
+				if fn, err := p.Lookup("Reload"); err == nil {
+					reloadFunc = fn.(func())
+				}
+
+				var previewFunc func(string) (string, string)
+
+				// optional, for providers sending lazy previews
+				if fn, err := p.Lookup("Preview"); err == nil {
+					previewFunc = fn.(func(string) (string, string))
+				}
+
+				var dumpFunc func() map[string]any
+
+				// optional, adds provider internals to `elephant debug dump`
+				if fn, err := p.Lookup("Dump"); err == nil {
+					dumpFunc = fn.(func() map[string]any)
+				}
+
+				var stopFunc func(context.Context)
+
+				// optional, called on shutdown
+				if fn, err := p.Lookup("Stop"); err == nil {
+					stopFunc = fn.(func(context.Context))
+				}
+
+				query := queryWithContext(queryFunc)
+				if query == nil {
+					slog.Error("providers", "load", "unsupported Query signature", "provider", path)
+					return nil
+				}
+
 				provider := Provider{
 					Icon:                 iconFunc.(func() string),
 					Setup:                setupFunc.(func()),
+					Reload:               reloadFunc,
+					Preview:              previewFunc,
+					Dump:                 dumpFunc,
+					Stop:                 stopFunc,
 					Name:                 name.(*string),
 					Activate:             activateFunc.(func(bool, string, string, string, string, uint8, net.Conn)),
-					Query:                queryFunc.(func(net.Conn, string, bool, bool, uint8) []*pb.QueryResponse_Item),
+					Query:                query,
 					NamePretty:           namePretty.(*string),
 					HideFromProviderlist: hideFromProviderlistFunc.(func() bool),
 					PrintDoc:             printDocFunc.(func()),
@@ -154,10 +203,21 @@ func Load(setup bool) {
 					State:                stateFunc.(func(string) *pb.ProviderStateResponse),
 				}
 
-				available := provider.Available()
+				enabled := common.ProviderEnabled(*provider.Name)
+
+				if !enabled {
+					slog.Info("providers", "disabled", *provider.Name)
+				}
+
+				available := enabled && provider.Available()
 
 				if setup && available {
-					go provider.Setup()
+					setupWG.Add(1)
+
+					go func() {
+						defer setupWG.Done()
+						provider.Setup()
+					}()
 				}
 
 				if available {
@@ -168,7 +228,7 @@ func Load(setup bool) {
 
 				slog.Info("providers", "loaded", *provider.Name)
 
-				if available {
+				if available || !enabled {
 					mut.Lock()
 					have = append(have, filepath.Base(path))
 					mut.Unlock()
@@ -183,4 +243,54 @@ func Load(setup bool) {
 			os.Exit(1)
 		}
 	}
+
+	loadExternal(setup, ignored)
+}
+
+// loadExternal adds the plugins configured in elephant.toml.
+func loadExternal(setup bool, ignored []string) {
+	for _, v := range common.GetElephantConfig().Plugins {
+		if v.Name == "" || v.Command == "" {
+			slog.Error("providers", "plugin", "name and command are required", "name", v.Name)
+			continue
+		}
+
+		if slices.Contains(ignored, v.Name) {
+			continue
+		}
+
+		if _, ok := Providers[v.Name]; ok {
+			slog.Error("providers", "plugin", "name already taken", "name", v.Name)
+			continue
+		}
+
+		provider := newExternal(v)
+
+		if setup {
+			setupWG.Add(1)
+
+			go func() {
+				defer setupWG.Done()
+				provider.Setup()
+			}()
+		}
+
+		Providers[v.Name] = provider
+
+		slog.Info("providers", "loaded", v.Name)
+	}
+}
+
+// queryWithContext supports providers built before Query received a context. Returns nil for unknown signatures.
+func queryWithContext(fn plugin.Symbol) func(context.Context, net.Conn, string, bool, bool, uint8) []*pb.QueryResponse_Item {
+	switch q := fn.(type) {
+	case func(context.Context, net.Conn, string, bool, bool, uint8) []*pb.QueryResponse_Item:
+		return q
+	case func(net.Conn, string, bool, bool, uint8) []*pb.QueryResponse_Item:
+		return func(_ context.Context, conn net.Conn, query string, single, exact bool, format uint8) []*pb.QueryResponse_Item {
+			return q(conn, query, single, exact, format)
+		}
+	}
+
+	return nil
 }
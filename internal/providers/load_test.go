@@ -0,0 +1,41 @@
+package providers
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+func TestQueryWithContext(t *testing.T) {
+	item := []*pb.QueryResponse_Item{{Text: "a"}}
+
+	current := func(context.Context, net.Conn, string, bool, bool, uint8) []*pb.QueryResponse_Item {
+		return item
+	}
+
+	legacy := func(net.Conn, string, bool, bool, uint8) []*pb.QueryResponse_Item {
+		return item
+	}
+
+	for name, fn := range map[string]any{"current": current, "legacy": legacy} {
+		q := queryWithContext(fn)
+		if q == nil {
+			t.Fatalf("%s: got nil", name)
+		}
+
+		if res := q(context.Background(), nil, "", false, false, 0); len(res) != 1 || res[0] != item[0] {
+			t.Errorf("%s: got %v", name, res)
+		}
+	}
+
+	for name, fn := range map[string]any{
+		"missing":   nil,
+		"signature": func(string) []*pb.QueryResponse_Item { return nil },
+	} {
+		if q := queryWithContext(fn); q != nil {
+			t.Errorf("%s: got a query function", name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"log/slog"
@@ -9,6 +10,7 @@ import (
 	"os/exec"
 	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -48,6 +50,43 @@ const (
 	ActionDefault  = "menus:default"
 )
 
+// time to confirm an entry by activating it again
+const confirmTimeout = 10 * time.Second
+
+var (
+	confirmMut sync.Mutex
+	confirms   = make(map[string]time.Time)
+)
+
+// confirm reports if the entry was activated before within confirmTimeout.
+// Otherwise it starts waiting for the confirmation.
+func confirm(identifier string) bool {
+	confirmMut.Lock()
+	defer confirmMut.Unlock()
+
+	if t, ok := confirms[identifier]; ok && time.Since(t) < confirmTimeout {
+		delete(confirms, identifier)
+		return true
+	}
+
+	confirms[identifier] = time.Now()
+
+	return false
+}
+
+func awaitsConfirmation(identifier string) bool {
+	confirmMut.Lock()
+	defer confirmMut.Unlock()
+
+	t, ok := confirms[identifier]
+
+	return ok && time.Since(t) < confirmTimeout
+}
+
+func confirmText(text string) string {
+	return fmt.Sprintf("%s: confirm?", text)
+}
+
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
 	switch action {
 	case ActionGoParent:
@@ -105,6 +144,16 @@ func Activate(single bool, identifier, action string, query string, args string,
 			return
 		}
 
+		if e.Confirm && !confirm(identifier) {
+			updated := itemToEntry(format, query, conn, menu.Actions, menu.NamePretty, single, menu.Icon, &e)
+			updated.Text = confirmText(e.Text)
+			handlers.UpdateItem(format, query, conn, updated)
+
+			handlers.ProviderUpdated <- fmt.Sprintf("%s:%s", Name, e.Menu)
+
+			return
+		}
+
 		run := ""
 
 		if after, ok := strings.CutPrefix(identifier, "dmenu:"); ok {
@@ -122,6 +171,10 @@ func Activate(single bool, identifier, action string, query string, args string,
 			}
 		}
 
+		if run == "" {
+			run = e.Exec
+		}
+
 		if run == "" {
 			if len(menu.Actions) != 0 {
 				if val, ok := menu.Actions[action]; ok {
@@ -221,7 +274,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 	}
 }
 
-func Query(conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item {
+func Query(_ context.Context, conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item {
 	start := time.Now()
 	entries := []*pb.QueryResponse_Item{}
 	menu := ""
@@ -235,8 +288,14 @@ func Query(conn net.Conn, query string, single bool, exact bool, format uint8) [
 		query = split[1]
 	}
 
+	flatten := false
+
+	if m, ok := common.Menus[menu]; ok {
+		flatten = m.FlattenSearch && query != ""
+	}
+
 	for _, v := range common.Menus {
-		if menu != "" && v.Name != menu {
+		if menu != "" && v.Name != menu && (!flatten || !v.IsSubMenuOf(menu)) {
 			continue
 		}
 
@@ -244,8 +303,22 @@ func Query(conn net.Conn, query string, single bool, exact bool, format uint8) [
 			v.CreateLuaEntries()
 		}
 
+		// global queries use the last output, so commands don't run for every menu
+		if v.CommandEntriesExpired() && (v.Name == menu || len(v.Entries) == 0 && menu != "") {
+			v.CreateCommandEntries()
+		}
+
 		for k, me := range v.Entries {
-			e := itemToEntry(format, query, conn, v.Actions, v.NamePretty, single, v.Icon, &v.Entries[k])
+			// entries of submenus show which menu they belong to
+			e := itemToEntry(format, query, conn, v.Actions, v.NamePretty, single && v.Name == menu, v.Icon, &v.Entries[k])
+
+			// a menu waiting for a confirmation only lists the entry to confirm
+			if v.Name == menu && me.Confirm && awaitsConfirmation(me.Identifier) {
+				e.Text = confirmText(e.Text)
+				e.Score = 0
+
+				return []*pb.QueryResponse_Item{e}
+			}
 
 			if v.FixedOrder {
 				e.Score = 1_000_000 - int32(k)
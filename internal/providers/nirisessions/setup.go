@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -192,6 +193,8 @@ func Activate(single bool, identifier, action string, query string, args string,
 			goWorkspaceDown()
 		}
 	}
+
+	common.RecordUsage(Name, identifier)
 }
 
 func goWorkspaceDown() {
@@ -207,7 +210,7 @@ func goWorkspaceDown() {
 	}
 }
 
-func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func Query(_ context.Context, conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	start := time.Now()
 
 	entries := []*pb.QueryResponse_Item{}
@@ -232,7 +235,11 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 			}
 		}
 
-		if query == "" || e.Score > config.MinScore {
+		if query == "" || e.Score > config.MinScoreFor(query) {
+			if query != "" && e.Score > 0 {
+				e.Score += common.UsageScore(Name, e.Identifier)
+			}
+
 			entries = append(entries, e)
 		}
 	}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"log/slog"
 	"net"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -56,14 +58,15 @@ func PrintDoc() {
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	common.RecordUsage(Name, identifier)
 }
 
-func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func Query(_ context.Context, conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	start := time.Now()
 	entries := []*pb.QueryResponse_Item{}
 
 	for _, v := range providers.Providers {
-		if *v.Name == Name || v.HideFromProviderlist() {
+		if *v.Name == Name || v.HideFromProviderlist() || !providers.Enabled(*v.Name) {
 			continue
 		}
 
@@ -83,6 +86,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 					Actions:    []string{"activate"},
 					Type:       pb.QueryResponse_REGULAR,
 					Icon:       v.Icon,
+					Metadata:   queryHints("menus"),
 				}
 
 				if query != "" {
@@ -103,7 +107,11 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 					}
 				}
 
-				if e.Score > config.MinScore || query == "" {
+				if e.Score > config.MinScoreFor(query) || query == "" {
+					if query != "" && e.Score > 0 {
+						e.Score += common.UsageScore(Name, e.Identifier)
+					}
+
 					entries = append(entries, e)
 				}
 			}
@@ -119,6 +127,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 				Provider:   Name,
 				Actions:    []string{"activate"},
 				Type:       pb.QueryResponse_REGULAR,
+				Metadata:   queryHints(*v.Name),
 			}
 
 			if query != "" {
@@ -129,7 +138,11 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 				e.Score, e.Fuzzyinfo.Positions, e.Fuzzyinfo.Start = common.FuzzyScore(query, e.Text, exact)
 			}
 
-			if e.Score > config.MinScore || query == "" {
+			if e.Score > config.MinScoreFor(query) || query == "" {
+				if query != "" && e.Score > 0 {
+					e.Score += common.UsageScore(Name, e.Identifier)
+				}
+
 				entries = append(entries, e)
 			}
 		}
@@ -163,3 +176,13 @@ func HideFromProviderlist() bool {
 func State(provider string) *pb.ProviderStateResponse {
 	return &pb.ProviderStateResponse{}
 }
+
+// queryHints lets frontends mirror the provider's query settings.
+func queryHints(provider string) map[string]string {
+	hints := common.GetQueryHints(provider)
+
+	return map[string]string{
+		"min_query_length": strconv.Itoa(hints.MinQueryLength),
+		"debounce_ms":      strconv.Itoa(hints.DebounceMs),
+	}
+}
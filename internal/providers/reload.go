@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/fsnotify/fsnotify"
+)
+
+// editors often write a file multiple times in a row
+const reloadDebounce = 300 * time.Millisecond
+
+// WatchConfig reloads providers when their config file changes. Providers
+// opt in by exporting a Reload function. onReload is called afterwards, f.e.
+// to notify subscribers.
+func WatchConfig(onReload func(provider string)) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Error("providers", "watchconfig", err)
+		return
+	}
+
+	for _, v := range common.ConfigDirs() {
+		if err := watcher.Add(v); err != nil {
+			slog.Error("providers", "watchconfig", err, "dir", v)
+		}
+	}
+
+	var mut sync.Mutex
+	timers := make(map[string]*time.Timer)
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			if filepath.Ext(event.Name) != ".toml" || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+
+			name := strings.TrimSuffix(filepath.Base(event.Name), ".toml")
+
+			p, ok := Providers[name]
+			if !ok || p.Reload == nil {
+				continue
+			}
+
+			mut.Lock()
+
+			if t, ok := timers[name]; ok {
+				t.Stop()
+			}
+
+			timers[name] = time.AfterFunc(reloadDebounce, func() {
+				slog.Info("providers", "reload", name)
+
+				p.Reload()
+				onReload(name)
+			})
+
+			mut.Unlock()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+
+			slog.Error("providers", "watchconfig", err)
+		}
+	}
+}
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"crypto/md5"
 	_ "embed"
 	"encoding/hex"
@@ -10,11 +11,10 @@ import (
 	"log/slog"
 	"net"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/abenz1267/elephant/v2/internal/util"
@@ -165,27 +165,20 @@ func Activate(single bool, identifier, action string, query string, args string,
 			}
 		}
 
-		run := strings.TrimSpace(fmt.Sprintf("%s %s %s", common.LaunchPrefix(""), bin, args))
-		if action == ActionRunInTerminal {
-			run = common.WrapWithTerminal(run)
-		}
-
-		cmd := exec.Command("sh", "-c", run)
-
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			Setsid: true,
-		}
-
-		err := cmd.Start()
+		pid, err := common.RunDetached(common.RunOpts{
+			Command:  strings.TrimSpace(fmt.Sprintf("%s %s", bin, args)),
+			Terminal: action == ActionRunInTerminal,
+		})
 		if err != nil {
 			slog.Error(Name, "activate", err)
+			common.ReportActivationError(conn, err)
 			return
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
 		}
 
+		common.ReportActivation(conn, common.ActivationResult{
+			Payload: strconv.Itoa(pid),
+		})
+
 		if config.History {
 			h.Save(query, identifier)
 		}
@@ -195,7 +188,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 	}
 }
 
-func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func Query(_ context.Context, conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	entries := []*pb.QueryResponse_Item{}
 
 	for _, v := range items {
@@ -232,13 +225,14 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 
 		var usageScore int32
 		if config.History {
-			if e.Score > config.MinScore || query == "" && config.HistoryWhenEmpty {
+			if e.Score > config.MinScoreFor(query) || query == "" && config.HistoryWhenEmpty {
 				usageScore = h.CalcUsageScore(query, e.Identifier)
 				e.Score = e.Score + usageScore
 			}
 		}
 
-		if e.Score > config.MinScore || query == "" {
+		if e.Score > config.MinScoreFor(query) || query == "" {
+			e.DedupKey = common.ExecutableKey(v.Bin)
 			entries = append(entries, e)
 		}
 	}
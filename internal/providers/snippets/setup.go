@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net"
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	_ "embed"
@@ -21,6 +23,7 @@ var (
 	Name       = "snippets"
 	NamePretty = "Snippets"
 	config     *Config
+	configMu   sync.RWMutex
 )
 
 //go:embed README.md
@@ -43,8 +46,8 @@ type Snippet struct {
 	Content  string   `koanf:"content" desc:"content to paste" default:""`
 }
 
-func Setup() {
-	config = &Config{
+func defaultConfig() *Config {
+	return &Config{
 		Config: common.Config{
 			Icon:     "insert-text",
 			MinScore: 50,
@@ -52,6 +55,10 @@ func Setup() {
 		Command: "wtype %CONTENT%",
 		Delay:   100,
 	}
+}
+
+func Setup() {
+	config = defaultConfig()
 
 	common.LoadConfig(Name, config)
 
@@ -60,6 +67,40 @@ func Setup() {
 	}
 }
 
+func Reload() {
+	c := defaultConfig()
+
+	if err := common.ReloadConfig(Name, c); err != nil {
+		slog.Error(Name, "reload", err)
+		return
+	}
+
+	configMu.Lock()
+	config = c
+	configMu.Unlock()
+
+	if c.NamePretty != "" {
+		NamePretty = c.NamePretty
+	}
+}
+
+// getConfig returns the current config. Reload replaces it, so it's not changed afterwards.
+func getConfig() *Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
+	return config
+}
+
+func Dump() map[string]any {
+	cfg := getConfig()
+
+	return map[string]any{
+		"items":  len(cfg.Snippets),
+		"config": cfg,
+	}
+}
+
 func Available() bool {
 	return true
 }
@@ -71,30 +112,41 @@ func PrintDoc() {
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
-	time.Sleep(time.Duration(config.Delay) * time.Millisecond)
+	cfg := getConfig()
+
+	i, err := strconv.Atoi(identifier)
+	if err != nil || i < 0 || i >= len(cfg.Snippets) {
+		slog.Error(Name, "activate", "unknown snippet", "identifier", identifier)
+		common.ReportActivationError(conn, fmt.Errorf("unknown snippet: %s", identifier))
+		return
+	}
+
+	s := cfg.Snippets[i]
 
-	i, _ := strconv.Atoi(identifier)
-	s := config.Snippets[i]
+	time.Sleep(time.Duration(cfg.Delay) * time.Millisecond)
 
-	toRun := strings.ReplaceAll(config.Command, "%CONTENT%", shellescape.Quote(s.Content))
+	toRun := strings.ReplaceAll(cfg.Command, "%CONTENT%", shellescape.Quote(s.Content))
 	cmd := exec.Command("sh", "-c", toRun)
 
-	err := cmd.Start()
+	err = cmd.Start()
 	if err != nil {
 		slog.Error(Name, "activate", err)
 	} else {
 		go func() {
 			cmd.Wait()
 		}()
+
+		common.RecordUsage(Name, identifier)
 	}
 }
 
-func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func Query(_ context.Context, conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	start := time.Now()
 
 	entries := []*pb.QueryResponse_Item{}
+	cfg := getConfig()
 
-	for k, v := range config.Snippets {
+	for k, v := range cfg.Snippets {
 		e := &pb.QueryResponse_Item{
 			Identifier: fmt.Sprintf("%d", k),
 			Text:       v.Name,
@@ -105,8 +157,13 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 			Type:       0,
 		}
 
+		found := false
+
 		if query != "" {
-			score, positions, start, found := calcScore(query, v, exact)
+			var score, start int32
+			var positions []int32
+
+			score, positions, start, found = calcScore(query, v, exact)
 
 			if found {
 				e.Score = score
@@ -118,7 +175,11 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 			}
 		}
 
-		if query == "" || e.Score > config.MinScore {
+		if query == "" || e.Score > cfg.MinScoreFor(query) {
+			if found {
+				e.Score += common.UsageScore(Name, e.Identifier)
+			}
+
 			entries = append(entries, e)
 		}
 	}
@@ -156,11 +217,11 @@ func calcScore(q string, d Snippet, exact bool) (int32, []int32, int32, bool) {
 }
 
 func Icon() string {
-	return config.Icon
+	return getConfig().Icon
 }
 
 func HideFromProviderlist() bool {
-	return config.HideFromProviderlist
+	return getConfig().HideFromProviderlist
 }
 
 func State(provider string) *pb.ProviderStateResponse {
@@ -0,0 +1,40 @@
+package providers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Stop calls the Stop function of all providers exporting one and waits until
+// they are done or the context expires.
+func Stop(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for name, p := range Providers {
+		if p.Stop == nil {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			p.Stop(ctx)
+			slog.Info("providers", "stopped", name)
+		}()
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Error("providers", "stop", ctx.Err())
+	}
+}
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"log"
@@ -113,7 +114,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 	}
 }
 
-func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func Query(_ context.Context, conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	start := time.Now()
 	entries := []*pb.QueryResponse_Item{}
 
@@ -145,14 +146,14 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 
 		var usageScore int32
 		if config.History {
-			if score > config.MinScore || query == "" && config.HistoryWhenEmpty {
+			if score > config.MinScoreFor(query) || query == "" && config.HistoryWhenEmpty {
 				usageScore = h.CalcUsageScore(query, k)
 
 				score = score + usageScore
 			}
 		}
 
-		if usageScore != 0 || score > config.MinScore || query == "" {
+		if usageScore != 0 || score > config.MinScoreFor(query) || query == "" {
 			state := []string{}
 
 			if usageScore != 0 {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"encoding/gob"
 	"fmt"
@@ -42,7 +43,7 @@ type Config struct {
 	UrgentTimeFrame   int        `koanf:"urgent_time_frame" desc:"items that have a due time within this period will be marked as urgent" default:"10"`
 	DuckPlayerVolumes bool       `koanf:"duck_player_volumes" desc:"lowers volume of players when notifying, slowly raises volumes again" default:"true"`
 	Categories        []Category `koanf:"categories" desc:"categories" default:""`
-	Location          string     `koanf:"location" desc:"location of the CSV file" default:"elephant cache dir"`
+	Location          string     `koanf:"location" desc:"location of the CSV file" default:"elephant cache dir" expand:"true"`
 	TimeFormat        string     `koanf:"time_format" desc:"format of the time. Look at https://go.dev/src/time/format.go for the layout." default:"02-Jan 15:04"`
 	Notification      `koanf:",squash"`
 	w                 *git.Worktree
@@ -522,7 +523,7 @@ func loadItems() {
 	loaded = true
 }
 
-func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func Query(_ context.Context, conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	if isGit && config.r == nil {
 		common.SetupGit(Name, config)
 		loadItems()
@@ -612,7 +613,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 			}
 
 			if date == nil {
-				if query == "" || e.Score > config.MinScore {
+				if query == "" || e.Score > config.MinScoreFor(query) {
 					entries = append(entries, e)
 				}
 			} else if isSameDay(date, &v.Scheduled) {
@@ -1,17 +1,16 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/url"
 	"os"
-	"os/exec"
 	"slices"
 	"strconv"
 	"strings"
-	"syscall"
 
 	"al.essio.dev/pkg/shellescape"
 	"github.com/abenz1267/elephant/v2/internal/comm/handlers"
@@ -53,8 +52,9 @@ type Engine struct {
 func Setup() {
 	config = &Config{
 		Config: common.Config{
-			Icon:     "applications-internet",
-			MinScore: 20,
+			Icon:           "applications-internet",
+			MinScore:       20,
+			MinQueryLength: 2,
 		},
 		History:          true,
 		HistoryWhenEmpty: false,
@@ -185,19 +185,11 @@ func Activate(single bool, identifier, action string, query string, args string,
 }
 
 func run(query, identifier, q string) {
-	cmd := exec.Command("sh", "-c", strings.TrimSpace(fmt.Sprintf("%s %s %s", common.LaunchPrefix(""), config.Command, shellescape.Quote(q))))
-
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setsid: true,
-	}
-
-	err := cmd.Start()
+	_, err := common.RunDetached(common.RunOpts{
+		Command: fmt.Sprintf("%s %s", config.Command, shellescape.Quote(q)),
+	})
 	if err != nil {
 		slog.Error(Name, "activate", err)
-	} else {
-		go func() {
-			cmd.Wait()
-		}()
 	}
 
 	if config.History {
@@ -205,7 +197,7 @@ func run(query, identifier, q string) {
 	}
 }
 
-func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func Query(_ context.Context, conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	entries := []*pb.QueryResponse_Item{}
 
 	prefix := ""
@@ -267,7 +259,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 
 				var usageScore int32
 				if config.History {
-					if e.Score > config.MinScore || query == "" && config.HistoryWhenEmpty {
+					if e.Score > config.MinScoreFor(query) || query == "" && config.HistoryWhenEmpty {
 						usageScore = h.CalcUsageScore(query, e.Identifier)
 
 						if usageScore != 0 {
@@ -279,7 +271,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 					}
 				}
 
-				if e.Score > config.MinScore || query == "" {
+				if e.Score > config.MinScoreFor(query) || query == "" {
 					entries = append(entries, e)
 				}
 			}
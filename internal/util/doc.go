@@ -12,6 +12,18 @@ import (
 )
 
 func GenerateDoc(provider string) {
+	if DocFormat == DocFormatJSON {
+		generateJSONDoc(func() {
+			printDoc(provider)
+		})
+
+		return
+	}
+
+	printDoc(provider)
+}
+
+func printDoc(provider string) {
 	provider = strings.ToLower(provider)
 	
 	if provider == "" || provider == "elephant" {
@@ -48,6 +60,11 @@ func GenerateDoc(provider string) {
 }
 
 func PrintConfig(c any, name string) {
+	if DocFormat == DocFormatJSON {
+		collectConfig(c, name)
+		return
+	}
+
 	fmt.Printf("`~/.config/elephant/%s.toml`\n", name)
 	printStructTable(c, getStructName(c))
 }
@@ -109,7 +126,7 @@ func printStructDesc(c any) {
 
 			if field.Type.Kind() == reflect.Slice {
 				elemType := field.Type.Elem()
-				if elemType.Kind() == reflect.Struct {
+				if elemType.Kind() == reflect.Struct && elemType != typ {
 					nestedStructs = append(nestedStructs, elemType)
 				}
 			}
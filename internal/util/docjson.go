@@ -0,0 +1,142 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/knadh/koanf/parsers/toml/v2"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+const (
+	DocFormatMarkdown = "markdown"
+	DocFormatJSON     = "json"
+)
+
+// DocFormat selects the output of GenerateDoc.
+var DocFormat = DocFormatMarkdown
+
+type ConfigDoc struct {
+	Provider string     `json:"provider"`
+	File     string     `json:"file"`
+	Fields   []FieldDoc `json:"fields"`
+}
+
+type FieldDoc struct {
+	Path        string `json:"path"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Default     string `json:"default"`
+	Value       any    `json:"value,omitempty"`
+}
+
+var configDocs []ConfigDoc
+
+// generateJSONDoc collects the configs printed by the providers' PrintDoc and
+// writes them as json. Everything else the providers print is discarded.
+func generateJSONDoc(print func()) {
+	stdout := os.Stdout
+
+	devnull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		slog.Error("doc", "devnull", err)
+		return
+	}
+
+	os.Stdout = devnull
+	configDocs = []ConfigDoc{}
+
+	print()
+
+	os.Stdout = stdout
+	devnull.Close()
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(configDocs); err != nil {
+		slog.Error("doc", "json", err)
+	}
+}
+
+func collectConfig(c any, name string) {
+	doc := ConfigDoc{
+		Provider: name,
+		File:     fmt.Sprintf("~/.config/elephant/%s.toml", name),
+		Fields:   []FieldDoc{},
+	}
+
+	var user *koanf.Koanf
+
+	if path, err := common.ProviderConfig(name); err == nil {
+		user = koanf.New(".")
+
+		if err := user.Load(file.Provider(path), toml.Parser()); err != nil {
+			slog.Error("doc", "config", err, "provider", name)
+			user = nil
+		}
+	}
+
+	doc.Fields = collectFields(reflect.TypeOf(c), "", user)
+
+	configDocs = append(configDocs, doc)
+}
+
+func collectFields(typ reflect.Type, prefix string, user *koanf.Koanf) []FieldDoc {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	res := []FieldDoc{}
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if field.Anonymous {
+			res = append(res, collectFields(field.Type, prefix, user)...)
+			continue
+		}
+
+		name := field.Tag.Get("koanf")
+
+		if name == "" {
+			name = field.Tag.Get("toml")
+		}
+
+		if name == "-" {
+			continue
+		}
+
+		path := prefix + name
+
+		f := FieldDoc{
+			Path:        path,
+			Type:        field.Type.String(),
+			Description: field.Tag.Get("desc"),
+			Default:     field.Tag.Get("default"),
+		}
+
+		if user != nil && prefix == "" && user.Exists(path) {
+			f.Value = user.Get(path)
+		}
+
+		res = append(res, f)
+
+		// structs containing themselves, like menu entries, are only listed once
+		if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct && field.Type.Elem() != typ {
+			res = append(res, collectFields(field.Type.Elem(), path+"[].", user)...)
+		}
+	}
+
+	return res
+}
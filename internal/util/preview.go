@@ -1,8 +1,12 @@
 package util
 
 var (
-	PreviewTypeText    = "text"
-	PreviewTypePango   = "pango"
+	PreviewTypeText  = "text"
+	PreviewTypePango = "pango"
+	// PreviewTypeCommand sent as lazy preview is run by elephant, which streams its output.
 	PreviewTypeCommand = "command"
 	PreviewTypeFile    = "file"
+	PreviewTypeImage   = "image"
+	// PreviewTypeLazy means the preview has to be requested with a PreviewRequest.
+	PreviewTypeLazy = "lazy"
 )
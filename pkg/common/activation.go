@@ -0,0 +1,115 @@
+package common
+
+import (
+	"net"
+	"sync"
+)
+
+const (
+	ActivationOk     = 0
+	ActivationFailed = 1
+)
+
+// ActivationResult is reported by providers to tell the client how an activation went.
+type ActivationResult struct {
+	Status  int32
+	Message string
+	// Payload is optional, f.e. the copied text or the pid of a launched process.
+	Payload string
+}
+
+// activations holds the results of running activations, keyed by connection.
+// Results reported after an activation ended are dropped.
+var (
+	activationsMu sync.Mutex
+	activations   = make(map[net.Conn]*ActivationResult)
+)
+
+// StartActivation lets ReportActivation store results for conn until EndActivation.
+func StartActivation(conn net.Conn) {
+	activationsMu.Lock()
+	defer activationsMu.Unlock()
+
+	activations[conn] = nil
+}
+
+// EndActivation drops the result for conn, if any, and ignores later reports.
+func EndActivation(conn net.Conn) {
+	activationsMu.Lock()
+	defer activationsMu.Unlock()
+
+	delete(activations, conn)
+}
+
+// ReportActivation sets the result of the activation currently handled for conn.
+func ReportActivation(conn net.Conn, res ActivationResult) {
+	if conn == nil {
+		return
+	}
+
+	activationsMu.Lock()
+	defer activationsMu.Unlock()
+
+	if _, ok := activations[conn]; ok {
+		activations[conn] = &res
+	}
+}
+
+// ReportActivationError reports a failed activation.
+func ReportActivationError(conn net.Conn, err error) {
+	ReportActivation(conn, ActivationResult{
+		Status:  ActivationFailed,
+		Message: err.Error(),
+	})
+}
+
+// TakeActivationResult returns and ends the reported result for conn.
+func TakeActivationResult(conn net.Conn) (ActivationResult, bool) {
+	activationsMu.Lock()
+	defer activationsMu.Unlock()
+
+	res := activations[conn]
+	delete(activations, conn)
+
+	if res == nil {
+		return ActivationResult{}, false
+	}
+
+	return *res, true
+}
+
+// Activation describes a successful activation. Text, Subtext and Icon are
+// taken from the item sent to the client, if it's known.
+type Activation struct {
+	Provider   string
+	Identifier string
+	Action     string
+	Query      string
+	Arguments  string
+	Text       string
+	Subtext    string
+	Icon       string
+}
+
+var (
+	activationListenersMut sync.RWMutex
+	activationListeners    []func(Activation)
+)
+
+// OnActivation registers a function called after every successful activation.
+func OnActivation(fn func(Activation)) {
+	activationListenersMut.Lock()
+	defer activationListenersMut.Unlock()
+
+	activationListeners = append(activationListeners, fn)
+}
+
+// NotifyActivation passes the activation to all registered listeners.
+func NotifyActivation(a Activation) {
+	activationListenersMut.RLock()
+	defer activationListenersMut.RUnlock()
+
+	for _, fn := range activationListeners {
+		fn(a)
+	}
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestActivationResults(t *testing.T) {
+	conn, other := net.Pipe()
+	defer conn.Close()
+	defer other.Close()
+
+	ReportActivation(conn, ActivationResult{Message: "not started"})
+
+	if _, ok := TakeActivationResult(conn); ok {
+		t.Error("result stored for an activation that wasn't started")
+	}
+
+	StartActivation(conn)
+	ReportActivationError(conn, errors.New("failed"))
+
+	res, ok := TakeActivationResult(conn)
+	if !ok || res.Status != ActivationFailed || res.Message != "failed" {
+		t.Errorf("got %+v, want the reported error", res)
+	}
+
+	// f.e. reported from a goroutine of the provider after the handler finished
+	ReportActivation(conn, ActivationResult{Message: "late"})
+
+	StartActivation(other)
+	ReportActivation(other, ActivationResult{Message: "unhandled"})
+	EndActivation(other)
+
+	activationsMu.Lock()
+	defer activationsMu.Unlock()
+
+	if len(activations) != 0 {
+		t.Errorf("got %d results left, want finished activations removed", len(activations))
+	}
+}
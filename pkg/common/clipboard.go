@@ -0,0 +1,54 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var ErrNoClipboardTool = errors.New("no clipboard tool found, install wl-clipboard, xclip or xsel")
+
+// CopyToClipboard copies content with the given mimetype, f.e. "text/plain" or "image/png".
+// Uses clipboard_command if configured, otherwise wl-copy, xclip or xsel.
+func CopyToClipboard(content []byte, mime string) error {
+	if mime == "" {
+		mime = "text/plain"
+	}
+
+	cmd, err := clipboardCmd(mime)
+	if err != nil {
+		return err
+	}
+
+	cmd.Stdin = bytes.NewReader(content)
+
+	return cmd.Run()
+}
+
+func clipboardCmd(mime string) (*exec.Cmd, error) {
+	if cfg := GetElephantConfig(); cfg != nil && cfg.ClipboardCommand != "" {
+		return exec.Command("sh", "-c", strings.ReplaceAll(cfg.ClipboardCommand, "%MIME%", mime)), nil
+	}
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if p, err := exec.LookPath("wl-copy"); err == nil {
+			return exec.Command(p, "-t", mime), nil
+		}
+	}
+
+	if p, err := exec.LookPath("xclip"); err == nil {
+		return exec.Command(p, "-selection", "clipboard", "-t", mime), nil
+	}
+
+	if p, err := exec.LookPath("xsel"); err == nil {
+		if !strings.HasPrefix(mime, "text/") {
+			return nil, errors.New("xsel only supports text")
+		}
+
+		return exec.Command(p, "--clipboard", "--input"), nil
+	}
+
+	return nil, ErrNoClipboardTool
+}
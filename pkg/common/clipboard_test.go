@@ -0,0 +1,131 @@
+package common
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeTools creates scripts recording their arguments and input in the returned
+// directory, which is the only entry of $PATH.
+func fakeTools(t *testing.T, names ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for _, v := range names {
+		script := "#!/bin/sh\nPATH=/bin:/usr/bin\necho \"$@\" > \"$0.args\"\ncat > \"$0.in\"\n"
+
+		if err := os.WriteFile(filepath.Join(dir, v), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv("PATH", dir)
+
+	return dir
+}
+
+func withElephantConfig(t *testing.T, cfg *ElephantConfig) {
+	t.Helper()
+
+	prev := elephantConfig
+	elephantConfig = cfg
+
+	t.Cleanup(func() {
+		elephantConfig = prev
+	})
+}
+
+func recorded(t *testing.T, dir, tool string) (string, string) {
+	t.Helper()
+
+	args, err := os.ReadFile(filepath.Join(dir, tool+".args"))
+	if err != nil {
+		t.Fatalf("%s wasn't run: %v", tool, err)
+	}
+
+	in, err := os.ReadFile(filepath.Join(dir, tool+".in"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.TrimSpace(string(args)), string(in)
+}
+
+func TestCopyToClipboardTools(t *testing.T) {
+	tests := []struct {
+		name    string
+		tools   []string
+		wayland string
+		mime    string
+		want    string
+		args    string
+	}{
+		{"wayland", []string{"wl-copy", "xclip", "xsel"}, "wayland-0", "image/png", "wl-copy", "-t image/png"},
+		{"default mimetype", []string{"wl-copy"}, "wayland-0", "", "wl-copy", "-t text/plain"},
+		{"x11 prefers xclip", []string{"wl-copy", "xclip", "xsel"}, "", "text/plain", "xclip", "-selection clipboard -t text/plain"},
+		{"xsel", []string{"xsel"}, "", "text/plain", "xsel", "--clipboard --input"},
+		{"wayland without wl-copy", []string{"xclip"}, "wayland-0", "text/plain", "xclip", "-selection clipboard -t text/plain"},
+	}
+
+	withElephantConfig(t, nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := fakeTools(t, tt.tools...)
+			t.Setenv("WAYLAND_DISPLAY", tt.wayland)
+
+			if err := CopyToClipboard([]byte("content"), tt.mime); err != nil {
+				t.Fatal(err)
+			}
+
+			args, in := recorded(t, dir, tt.want)
+
+			if args != tt.args || in != "content" {
+				t.Errorf("got args %q and input %q, want %q and %q", args, in, tt.args, "content")
+			}
+		})
+	}
+}
+
+func TestCopyToClipboardCommand(t *testing.T) {
+	dir := fakeTools(t, "wl-copy", "mycopy")
+	t.Setenv("PATH", dir+":/bin:/usr/bin")
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+
+	withElephantConfig(t, &ElephantConfig{ClipboardCommand: "mycopy --type %MIME%"})
+
+	if err := CopyToClipboard([]byte("content"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	args, in := recorded(t, dir, "mycopy")
+
+	if args != "--type image/png" || in != "content" {
+		t.Errorf("got args %q and input %q", args, in)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "wl-copy.args")); err == nil {
+		t.Error("wl-copy was run despite clipboard_command")
+	}
+}
+
+func TestCopyToClipboardErrors(t *testing.T) {
+	withElephantConfig(t, nil)
+	t.Setenv("WAYLAND_DISPLAY", "")
+
+	fakeTools(t)
+
+	if err := CopyToClipboard([]byte("content"), ""); !errors.Is(err, ErrNoClipboardTool) {
+		t.Errorf("got %v, want ErrNoClipboardTool", err)
+	}
+
+	fakeTools(t, "xsel")
+
+	if err := CopyToClipboard([]byte("content"), "image/png"); err == nil {
+		t.Error("xsel accepted an image")
+	}
+}
@@ -5,19 +5,65 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"unicode/utf8"
 
 	"github.com/joho/godotenv"
 	"github.com/knadh/koanf/parsers/toml/v2"
-	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/providers/structs"
 	"github.com/knadh/koanf/v2"
 )
 
 type Config struct {
-	Icon                 string `koanf:"icon" desc:"icon for provider" default:"depends on provider"`
-	NamePretty           string `koanf:"name_pretty" desc:"displayed name for the provider" default:"depends on provider"`
-	MinScore             int32  `koanf:"min_score" desc:"minimum score for items to be displayed" default:"depends on provider"`
-	HideFromProviderlist bool   `koanf:"hide_from_providerlist" desc:"hides a provider from the providerlist provider. provider provider." default:"false"`
+	Icon                 string  `koanf:"icon" desc:"icon for provider" default:"depends on provider"`
+	NamePretty           string  `koanf:"name_pretty" desc:"displayed name for the provider" default:"depends on provider"`
+	MinScore             int32   `koanf:"min_score" desc:"minimum score for items to be displayed" default:"depends on provider"`
+	MinScoreShort        int32   `koanf:"min_score_short" desc:"minimum score for queries of up to 2 characters, which score low across the board. 0 uses min_score" default:"0"`
+	HideFromProviderlist bool    `koanf:"hide_from_providerlist" desc:"hides a provider from the providerlist provider. provider provider." default:"false"`
+	LogLevel             string  `koanf:"log_level" desc:"log level for this provider: debug, info, warn or error. uses the global level if empty" default:""`
+	MinQueryLength       int     `koanf:"min_query_length" desc:"shorter queries return no results from this provider. empty queries are not affected" default:"depends on provider"`
+	DebounceMs           int     `koanf:"debounce_ms" desc:"hint for frontends to debounce queries to this provider, in ms" default:"0"`
+	Weight               float64 `koanf:"weight" desc:"multiplies the normalized scores of this provider when merged with results of other providers. 0 is treated as 1" default:"1"`
+	// Enabled is checked with ProviderEnabled before the provider is set up.
+	Enabled bool `koanf:"enabled" desc:"false keeps the provider installed, but doesn't set it up or make it available" default:"true"`
+}
+
+// short queries can't score high, so they get their own threshold
+const shortQueryLength = 2
+
+// MinScoreFor returns the minimum score for the given query.
+func (c *Config) MinScoreFor(query string) int32 {
+	if c.MinScoreShort != 0 && utf8.RuneCountInString(query) <= shortQueryLength {
+		return c.MinScoreShort
+	}
+
+	return c.MinScore
+}
+
+func (c *Config) providerLogLevel() string {
+	return c.LogLevel
+}
+
+func (c *Config) providerQueryHints() QueryHints {
+	return QueryHints{
+		MinQueryLength: c.MinQueryLength,
+		DebounceMs:     c.DebounceMs,
+	}
+}
+
+func (c *Config) providerWeight() float64 {
+	return c.Weight
+}
+
+// providerDefaults sets defaults shared by all providers, so their config literals don't have to.
+func (c *Config) providerDefaults() {
+	c.Enabled = true
+}
+
+type providerConfig interface {
+	providerLogLevel() string
+	providerQueryHints() QueryHints
+	providerWeight() float64
+	providerDefaults()
 }
 
 type Command struct {
@@ -26,11 +72,28 @@ type Command struct {
 }
 
 type ElephantConfig struct {
-	AutoDetectLaunchPrefix bool      `koanf:"auto_detect_launch_prefix" desc:"automatically detects uwsm, app2unit or systemd-run" default:"true"`
-	OverloadLocalEnv       bool      `koanf:"overload_local_env" desc:"overloads the local env" default:"false"`
-	IgnoredProviders       []string  `koanf:"ignored_providers" desc:"providers to ignore" default:"<empty>"`
-	GitOnDemand            bool      `koanf:"git_on_demand" desc:"sets up git repositories on first query instead of on start" default:"true"`
-	BeforeLoad             []Command `koanf:"before_load" desc:"commands to run before starting to load the providers" default:""`
+	AutoDetectLaunchPrefix bool              `koanf:"auto_detect_launch_prefix" desc:"automatically detects uwsm, app2unit or systemd-run" default:"true"`
+	OverloadLocalEnv       bool              `koanf:"overload_local_env" desc:"overloads the local env" default:"false"`
+	IgnoredProviders       []string          `koanf:"ignored_providers" desc:"providers to ignore" default:"<empty>"`
+	GitOnDemand            bool              `koanf:"git_on_demand" desc:"sets up git repositories on first query instead of on start" default:"true"`
+	BeforeLoad             []Command         `koanf:"before_load" desc:"commands to run before starting to load the providers" default:""`
+	QueryTimeout           int               `koanf:"query_timeout" desc:"max time in ms a provider can take to answer a query. 0 to disable" default:"0"`
+	ProviderQueryTimeouts  map[string]int    `koanf:"provider_query_timeouts" desc:"query_timeout per provider, f.e. files = 500" default:""`
+	PreferSystemdRun       bool              `koanf:"prefer_systemd_run" desc:"launches programs in a systemd-run scope, so they survive elephant restarts. ignored if a provider sets a launch_prefix" default:"false"`
+	IconTheme              string            `koanf:"icon_theme" desc:"icon theme used to resolve icon paths. detected from gsettings or gtk settings if empty" default:""`
+	ResolveIconPaths       bool              `koanf:"resolve_icon_paths" desc:"send absolute icon paths instead of icon names" default:"false"`
+	ClipboardCommand       string            `koanf:"clipboard_command" desc:"command used to copy, gets the content via stdin. supports %MIME%. detects wl-copy, xclip or xsel if empty" default:""`
+	Socket                 string            `koanf:"socket" desc:"path of the socket. its directory is restricted to the user" default:"$XDG_RUNTIME_DIR/elephant/elephant.sock" expand:"true"`
+	SocketSecretFile       string            `koanf:"socket_secret_file" desc:"file containing a secret clients have to send before any other request" default:"" expand:"true"`
+	QueryPrefixes          map[string]string `koanf:"query_prefixes" desc:"prefixes routing combined queries to a single provider" default:"= calc, ; symbols, ! websearch"`
+	Plugins                []ExternalPlugin  `koanf:"plugins" desc:"external providers, programs speaking json lines on stdin/stdout" default:""`
+}
+
+type ExternalPlugin struct {
+	Name       string `koanf:"name" desc:"name of the provider" default:""`
+	NamePretty string `koanf:"name_pretty" desc:"displayed name of the provider" default:""`
+	Icon       string `koanf:"icon" desc:"icon of the provider" default:""`
+	Command    string `koanf:"command" desc:"command starting the plugin, run with sh -c" default:""`
 }
 
 var elephantConfig *ElephantConfig
@@ -40,6 +103,11 @@ func LoadGlobalConfig() {
 		AutoDetectLaunchPrefix: true,
 		OverloadLocalEnv:       false,
 		GitOnDemand:            true,
+		QueryPrefixes: map[string]string{
+			"=": "calc",
+			";": "symbols",
+			"!": "websearch",
+		},
 	}
 
 	LoadConfig("elephant", elephantConfig)
@@ -71,37 +139,114 @@ func GetElephantConfig() *ElephantConfig {
 }
 
 func LoadConfig(provider string, config any) {
-	defaults := koanf.New(".")
+	if err := loadConfig(provider, config); err != nil {
+		slog.Error(provider, "config", err)
+		os.Exit(1)
+	}
+}
 
-	err := defaults.Load(structs.Provider(config, "koanf"), nil)
+// ProviderEnabled checks the `enabled` option of a provider's config, before the provider loads it.
+func ProviderEnabled(provider string) bool {
+	file, err := ProviderConfig(provider)
 	if err != nil {
+		return true
+	}
+
+	var config struct {
+		Config `koanf:",squash"`
+	}
+
+	if _, err := readConfig(file, &config); err != nil {
 		slog.Error(provider, "config", err)
-		os.Exit(1)
+		return true
 	}
 
+	return config.Enabled
+}
+
+// ReloadConfig loads the config like LoadConfig, but returns errors instead of exiting.
+func ReloadConfig(provider string, config any) error {
+	return loadConfig(provider, config)
+}
+
+func loadConfig(provider string, config any) error {
 	userConfig, err := ProviderConfig(provider)
 	if err != nil {
 		slog.Info(provider, "config", "using default config")
-		return
+		userConfig = ""
 	}
 
+	warnings, err := readConfig(userConfig, config)
+	if err != nil {
+		return err
+	}
+
+	setConfigWarnings(provider, warnings)
+
+	return applyProviderConfig(provider, config)
+}
+
+// readConfig merges the user config file into config and returns warnings for
+// keys it doesn't know. Without a file, config keeps its defaults.
+func readConfig(file string, config any) ([]string, error) {
+	if c, ok := config.(providerConfig); ok {
+		c.providerDefaults()
+	}
+
+	if file == "" {
+		expandConfig(config)
+		return nil, nil
+	}
+
+	defaults := koanf.New(".")
+
+	err := defaults.Load(structs.Provider(config, "koanf"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := toml.Parser().Unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := validateConfig(raw, config)
+
 	user := koanf.New("")
 
-	err = user.Load(file.Provider(userConfig), toml.Parser())
+	err = user.Load(mapProvider(raw), nil)
 	if err != nil {
-		slog.Error(provider, "config", err)
-		os.Exit(1)
+		return nil, err
 	}
 
 	err = defaults.Merge(user)
 	if err != nil {
-		slog.Error(provider, "config", err)
-		os.Exit(1)
+		return nil, err
 	}
 
 	err = defaults.Unmarshal("", &config)
 	if err != nil {
-		slog.Error(provider, "config", err)
-		os.Exit(1)
+		return nil, err
+	}
+
+	expandConfig(config)
+
+	return warnings, nil
+}
+
+func applyProviderConfig(provider string, config any) error {
+	c, ok := config.(providerConfig)
+	if !ok {
+		return nil
 	}
+
+	setQueryHints(provider, c.providerQueryHints())
+	setWeight(provider, c.providerWeight())
+
+	return SetLogLevel(provider, c.providerLogLevel())
 }
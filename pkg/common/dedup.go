@@ -0,0 +1,55 @@
+package common
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	execKeyMut sync.Mutex
+	execKeys   = make(map[string]string)
+)
+
+// ExecutableKey returns the resolved path of the executable a command runs,
+// to be used as DedupKey. Leading env assignments are skipped. Empty if the
+// executable can't be found.
+func ExecutableKey(command string) string {
+	bin := ""
+
+	for v := range strings.FieldsSeq(command) {
+		v = strings.Trim(v, `"'`)
+
+		if v == "env" || strings.Contains(v, "=") {
+			continue
+		}
+
+		bin = v
+		break
+	}
+
+	if bin == "" {
+		return ""
+	}
+
+	execKeyMut.Lock()
+	defer execKeyMut.Unlock()
+
+	if k, ok := execKeys[bin]; ok {
+		return k
+	}
+
+	k, err := exec.LookPath(bin)
+	if err == nil {
+		if resolved, err := filepath.EvalSymlinks(k); err == nil {
+			k = resolved
+		}
+	} else {
+		k = ""
+	}
+
+	execKeys[bin] = k
+
+	return k
+}
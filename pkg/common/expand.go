@@ -0,0 +1,141 @@
+package common
+
+import (
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/adrg/xdg"
+)
+
+// expandConfig expands "~", $HOME and $XDG_* variables in the string fields of
+// the given config tagged with `expand:"true"`, including strings in tagged
+// slices and maps. Nested structs are walked, their fields need their own tag.
+// "$$" is an escaped "$". Other variables are kept as is, as they are likely
+// meant for a shell.
+func expandConfig(config any) {
+	expandValue(reflect.ValueOf(config), false)
+}
+
+func expandValue(v reflect.Value, expand bool) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			expandValue(v.Elem(), expand)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if f := v.Type().Field(i); f.IsExported() {
+				expandValue(v.Field(i), f.Tag.Get("expand") == "true")
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			expandValue(v.Index(i), expand)
+		}
+	case reflect.Map:
+		if !expand && v.Type().Elem().Kind() != reflect.Struct {
+			return
+		}
+
+		iter := v.MapRange()
+		for iter.Next() {
+			// map values aren't addressable, so they are expanded on a copy
+			val := reflect.New(v.Type().Elem()).Elem()
+			val.Set(iter.Value())
+			expandValue(val, expand)
+			v.SetMapIndex(iter.Key(), val)
+		}
+	case reflect.String:
+		if expand && v.CanSet() {
+			v.SetString(ExpandVars(v.String()))
+		}
+	}
+}
+
+// ExpandVars expands a leading "~" as well as $HOME and $XDG_* variables.
+func ExpandVars(s string) string {
+	if !strings.ContainsAny(s, "~$") {
+		return s
+	}
+
+	if s == "~" || strings.HasPrefix(s, "~/") {
+		s = xdg.Home + s[1:]
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		if s[i+1] == '$' {
+			b.WriteByte('$')
+			i++
+			continue
+		}
+
+		name, length := varName(s[i+1:])
+
+		if val, ok := lookupVar(name); ok {
+			b.WriteString(val)
+			i += length
+			continue
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+// varName parses "NAME" or "{NAME}" and returns the name and the consumed length.
+func varName(s string) (string, int) {
+	if strings.HasPrefix(s, "{") {
+		end := strings.IndexByte(s, '}')
+		if end == -1 {
+			return "", 0
+		}
+
+		return s[1:end], end + 1
+	}
+
+	i := 0
+
+	for i < len(s) && (s[i] == '_' || s[i] >= 'A' && s[i] <= 'Z' || s[i] >= 'a' && s[i] <= 'z' || s[i] >= '0' && s[i] <= '9') {
+		i++
+	}
+
+	return s[:i], i
+}
+
+func lookupVar(name string) (string, bool) {
+	if name == "HOME" {
+		return xdg.Home, true
+	}
+
+	if !strings.HasPrefix(name, "XDG_") {
+		return "", false
+	}
+
+	if val, ok := os.LookupEnv(name); ok {
+		return val, true
+	}
+
+	switch name {
+	case "XDG_CONFIG_HOME":
+		return xdg.ConfigHome, true
+	case "XDG_DATA_HOME":
+		return xdg.DataHome, true
+	case "XDG_CACHE_HOME":
+		return xdg.CacheHome, true
+	case "XDG_STATE_HOME":
+		return xdg.StateHome, true
+	case "XDG_RUNTIME_DIR":
+		return xdg.RuntimeDir, true
+	}
+
+	return "", false
+}
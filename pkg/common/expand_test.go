@@ -0,0 +1,114 @@
+package common
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/adrg/xdg"
+)
+
+type expandInner struct {
+	Path    string `expand:"true"`
+	Command string
+}
+
+type expandConfigTest struct {
+	Config   `koanf:",squash"`
+	Location string                 `expand:"true"`
+	Dirs     []string               `expand:"true"`
+	Named    map[string]string      `expand:"true"`
+	Ptr      *string                `expand:"true"`
+	Command  string                 `koanf:"command"`
+	Args     []string               `koanf:"args"`
+	Inner    expandInner            `koanf:"inner"`
+	Inners   []expandInner          `koanf:"inners"`
+	ByName   map[string]expandInner `koanf:"by_name"`
+	Nested   *expandInner           `koanf:"nested"`
+	internal string
+}
+
+func TestExpandVars(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/cfg")
+	t.Setenv("PATH", "/bin")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"~", xdg.Home},
+		{"~/x", xdg.Home + "/x"},
+		{"a~/x", "a~/x"},
+		{"$HOME/x", xdg.Home + "/x"},
+		{"${HOME}x", xdg.Home + "x"},
+		{"$XDG_CONFIG_HOME/elephant", "/cfg/elephant"},
+		{"${XDG_CONFIG_HOME}/elephant", "/cfg/elephant"},
+		{"$PATH", "$PATH"},
+		{"$$HOME", "$HOME"},
+		{"cost: 5$", "cost: 5$"},
+		{"${HOME", "${HOME"},
+	}
+
+	for _, tt := range tests {
+		if got := ExpandVars(tt.in); got != tt.want {
+			t.Errorf("ExpandVars(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExpandConfig(t *testing.T) {
+	ptr := "~/ptr"
+
+	c := &expandConfigTest{
+		Config:   Config{Icon: "~/icon.png"},
+		Location: "~/loc",
+		Dirs:     []string{"~/a", "$HOME/b"},
+		Named:    map[string]string{"x": "~/x"},
+		Ptr:      &ptr,
+		Command:  "ls ~ $HOME",
+		Args:     []string{"~"},
+		Inner:    expandInner{Path: "~/inner", Command: "cd ~"},
+		Inners:   []expandInner{{Path: "~/0"}, {Path: "~/1", Command: "cd ~"}},
+		ByName:   map[string]expandInner{"k": {Path: "~/k", Command: "cd ~"}},
+		Nested:   &expandInner{Path: "~/nested", Command: "cd ~"},
+		internal: "~",
+	}
+
+	expandConfig(c)
+
+	home := xdg.Home
+
+	checks := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"untagged embedded", c.Icon, "~/icon.png"},
+		{"string", c.Location, home + "/loc"},
+		{"map", c.Named["x"], home + "/x"},
+		{"pointer", *c.Ptr, home + "/ptr"},
+		{"untagged string", c.Command, "ls ~ $HOME"},
+		{"nested struct", c.Inner.Path, home + "/inner"},
+		{"nested untagged", c.Inner.Command, "cd ~"},
+		{"slice of structs", c.Inners[1].Path, home + "/1"},
+		{"slice of structs untagged", c.Inners[1].Command, "cd ~"},
+		{"map of structs", c.ByName["k"].Path, home + "/k"},
+		{"map of structs untagged", c.ByName["k"].Command, "cd ~"},
+		{"pointer to struct", c.Nested.Path, home + "/nested"},
+		{"pointer to struct untagged", c.Nested.Command, "cd ~"},
+		{"unexported", c.internal, "~"},
+	}
+
+	for _, v := range checks {
+		if v.got != v.want {
+			t.Errorf("%s: got %q, want %q", v.name, v.got, v.want)
+		}
+	}
+
+	if want := []string{home + "/a", home + "/b"}; !slices.Equal(c.Dirs, want) {
+		t.Errorf("slice: got %v, want %v", c.Dirs, want)
+	}
+
+	if !slices.Equal(c.Args, []string{"~"}) {
+		t.Errorf("untagged slice: got %v", c.Args)
+	}
+}
@@ -52,6 +52,10 @@ func CacheFile(file string) string {
 	return filepath.Join(d, "elephant", file)
 }
 
+func StateFile(file string) string {
+	return filepath.Join(xdg.StateHome, "elephant", file)
+}
+
 var ErrConfigNotExists = errors.New("provider config doesn't exist")
 
 func ProviderConfig(provider string) (string, error) {
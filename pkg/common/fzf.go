@@ -3,26 +3,83 @@ package common
 import (
 	"slices"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/junegunn/fzf/src/algo"
 	"github.com/junegunn/fzf/src/util"
+	"golang.org/x/text/unicode/norm"
 )
 
 func init() {
 	algo.Init("default")
 }
 
+// FuzzyScore matches input against target. Both are folded with NFKD and
+// stripped of diacritics, so "uber" matches "Über". Positions and start are
+// rune indices into target. The input can select a different match mode, see
+// ParseMatchMode.
 func FuzzyScore(input, target string, exact bool) (int32, []int32, int32) {
-	runes := []rune(input)
-	chars := util.ToChars([]byte(target))
+	query, mode := ParseMatchMode(input)
+
+	return matchMode(query, target, mode, exact)
+}
+
+// FuzzyScorePath is FuzzyScore for providers searching paths, see ParsePathMatchMode.
+func FuzzyScorePath(input, target string, exact bool) (int32, []int32, int32) {
+	query, mode := ParsePathMatchMode(input)
+
+	return matchMode(query, target, mode, exact)
+}
+
+func matchMode(query, target string, mode MatchMode, exact bool) (int32, []int32, int32) {
+	if exact && mode == MatchFuzzy {
+		mode = MatchExact
+	}
+
+	return Match(query, target, mode)
+}
+
+func fzfScore(input, target string, exact bool) (int32, []int32, int32) {
+	runes := fold(input)
+	caseSensitive := slices.ContainsFunc(runes, unicode.IsUpper)
+
+	if !caseSensitive {
+		for i, r := range runes {
+			runes[i] = unicode.ToLower(r)
+		}
+	}
+
+	var chars util.Chars
+
+	if isASCII(target) {
+		chars = util.ToChars([]byte(target))
+	} else {
+		chars = util.RunesToChars(fold(target))
+	}
 
 	var res algo.Result
 	var pos *[]int
 
 	if exact {
-		res, pos = algo.ExactMatchNaive(slices.ContainsFunc(runes, unicode.IsUpper), true, true, &chars, runes, true, nil)
+		res, pos = algo.ExactMatchNaive(caseSensitive, true, true, &chars, runes, true, nil)
 	} else {
-		res, pos = algo.FuzzyMatchV2(slices.ContainsFunc(runes, unicode.IsUpper), true, true, &chars, runes, true, nil)
+		res, pos = algo.FuzzyMatchV2(caseSensitive, true, true, &chars, runes, true, nil)
+
+		if p := initialism(caseSensitive, &chars, runes); p != nil {
+			res.Score += initialismBonus * len(runes)
+			res.Start = p[len(p)-1]
+			pos = &p
+		}
+	}
+
+	if exact && pos == nil && res.Start >= 0 {
+		p := make([]int, 0, res.End-res.Start)
+
+		for i := res.End - 1; i >= res.Start; i-- {
+			p = append(p, i)
+		}
+
+		pos = &p
 	}
 
 	var int32Slice []int32
@@ -42,3 +99,91 @@ func FuzzyScore(input, target string, exact bool) (int32, []int32, int32) {
 
 	return int32(res.Score), int32Slice, int32(res.Start)
 }
+
+// initialismBonus is added per pattern rune if the pattern matches the
+// initials of the words in the target.
+const initialismBonus = 8
+
+// initialism matches pattern against word starts, f.e. "fft" against
+// "Firefox File Transfer". Words start after separators and at camelCase
+// humps. Positions are returned in descending order, like fzf does.
+func initialism(caseSensitive bool, chars *util.Chars, pattern []rune) []int {
+	if len(pattern) < 2 {
+		return nil
+	}
+
+	pos := make([]int, 0, len(pattern))
+	prev := ' '
+
+	for i := 0; i < chars.Length() && len(pos) < len(pattern); i++ {
+		c := chars.Get(i)
+
+		word := unicode.IsLetter(c) || unicode.IsDigit(c)
+		start := !unicode.IsLetter(prev) && !unicode.IsDigit(prev) || unicode.IsLower(prev) && unicode.IsUpper(c)
+
+		if !caseSensitive {
+			c = unicode.ToLower(c)
+		}
+
+		if word && start && c == pattern[len(pos)] {
+			pos = append(pos, i)
+		}
+
+		prev = chars.Get(i)
+	}
+
+	if len(pos) < len(pattern) {
+		return nil
+	}
+
+	slices.Reverse(pos)
+
+	return pos
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fold replaces every rune with its NFKD base rune. Runes decomposing into
+// more than one base rune are kept as is, so indices stay aligned with the
+// original string.
+func fold(s string) []rune {
+	runes := []rune(s)
+
+	for i, r := range runes {
+		if r >= utf8.RuneSelf {
+			runes[i] = foldRune(r)
+		}
+	}
+
+	return runes
+}
+
+func foldRune(r rune) rune {
+	base := rune(-1)
+
+	for _, c := range norm.NFKD.String(string(r)) {
+		if unicode.Is(unicode.Mn, c) {
+			continue
+		}
+
+		if base != -1 {
+			return r
+		}
+
+		base = c
+	}
+
+	if base == -1 {
+		return r
+	}
+
+	return base
+}
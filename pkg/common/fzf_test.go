@@ -0,0 +1,130 @@
+package common
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/junegunn/fzf/src/util"
+)
+
+// paths returns n file paths, similar to what the files provider scores.
+func paths(n int) []string {
+	res := make([]string, 0, n)
+
+	for i := range n {
+		res = append(res, fmt.Sprintf("/home/user/projects/project-%d/src/internal/module_%d/file_%d.go", i%50, i%300, i))
+	}
+
+	return res
+}
+
+func TestFuzzyScoreRanking(t *testing.T) {
+	tests := []struct {
+		query  string
+		better string
+		worse  string
+	}{
+		{"fft", "Firefox File Transfer", "Fluffy Tiger"},
+		{"fft", "Firefox File Transfer", "offtopic"},
+		{"gc", "Google Chrome", "Magic Lantern"},
+		{"vsc", "VisualStudioCode", "Avs Converter"},
+		{"lo", "LibreOffice", "Hello"},
+		{"ps", "Power Settings", "Apps"},
+		{"tb", "Text Book", "Thunderbird"},
+		{"uber", "Über", "Unterbrecher"},
+	}
+
+	for _, tt := range tests {
+		better, _, _ := FuzzyScore(tt.query, tt.better, false)
+		worse, _, _ := FuzzyScore(tt.query, tt.worse, false)
+
+		if better <= worse {
+			t.Errorf("%q: %q (%d) doesn't outrank %q (%d)", tt.query, tt.better, better, tt.worse, worse)
+		}
+	}
+}
+
+func TestInitialism(t *testing.T) {
+	tests := []struct {
+		pattern string
+		target  string
+		want    []int
+	}{
+		{"fft", "Firefox File Transfer", []int{13, 8, 0}},
+		{"vsc", "VisualStudioCode", []int{12, 6, 0}},
+		{"gc", "google-chrome", []int{7, 0}},
+		{"gc", "my_gnome_config", []int{9, 3}},
+		{"v2", "Version 2", []int{8, 0}},
+		{"fft", "Fluffy Tiger", nil},
+		{"f", "Firefox", nil},
+		{"ab", "a", nil},
+	}
+
+	for _, tt := range tests {
+		chars := util.ToChars([]byte(tt.target))
+
+		if got := initialism(false, &chars, []rune(tt.pattern)); !slices.Equal(got, tt.want) {
+			t.Errorf("%q in %q: got %v, want %v", tt.pattern, tt.target, got, tt.want)
+		}
+	}
+
+	chars := util.ToChars([]byte("firefox file"))
+
+	if got := initialism(true, &chars, []rune("Ff")); got != nil {
+		t.Errorf("case sensitive pattern matched lowercase initials: %v", got)
+	}
+}
+
+func TestFuzzyScoreInitialismPositions(t *testing.T) {
+	_, positions, start := FuzzyScore("fft", "Firefox File Transfer", false)
+
+	if !slices.Equal(positions, []int32{13, 8, 0}) || start != 0 {
+		t.Errorf("got positions %v and start %d, want the initials", positions, start)
+	}
+}
+
+func BenchmarkFuzzyScoreASCII(b *testing.B) {
+	for b.Loop() {
+		FuzzyScore("fire", "Firefox Web Browser", false)
+	}
+}
+
+func BenchmarkFuzzyScoreUnicode(b *testing.B) {
+	for b.Loop() {
+		FuzzyScore("uber", "Über die Größe von Ärger", false)
+	}
+}
+
+func BenchmarkFold(b *testing.B) {
+	s := strings.Repeat("Crème brûlée à la façon de Zoë ", 8)
+
+	for b.Loop() {
+		fold(s)
+	}
+}
+
+func BenchmarkFuzzyScorePaths(b *testing.B) {
+	haystack := paths(20000)
+
+	for b.Loop() {
+		for _, v := range haystack {
+			FuzzyScore("mod12file", v, false)
+		}
+	}
+}
+
+func BenchmarkFuzzyScorePathsUnicode(b *testing.B) {
+	haystack := paths(20000)
+
+	for i, v := range haystack {
+		haystack[i] = strings.ReplaceAll(v, "project", "projèct")
+	}
+
+	for b.Loop() {
+		for _, v := range haystack {
+			FuzzyScore("projectfile", v, false)
+		}
+	}
+}
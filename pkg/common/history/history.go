@@ -41,6 +41,16 @@ func (h *History) Remove(identifier string) {
 	h.writeFile()
 }
 
+// Clear removes the whole history.
+func (h *History) Clear() {
+	mut.Lock()
+	defer mut.Unlock()
+
+	h.Data = make(map[string]map[string]*HistoryData)
+
+	h.writeFile()
+}
+
 func (h *History) Save(query, identifier string) {
 	mut.Lock()
 	defer mut.Unlock()
@@ -0,0 +1,346 @@
+package common
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+var iconExtensions = []string{".png", ".svg", ".xpm"}
+
+type iconDir struct {
+	path      string
+	size      int
+	scale     int
+	minSize   int
+	maxSize   int
+	threshold int
+	kind      string
+}
+
+type iconTheme struct {
+	inherits []string
+	dirs     []iconDir
+}
+
+// how often the current theme is checked for changes
+const iconThemeCheckInterval = 10 * time.Second
+
+var (
+	icons          = make(map[string]string)
+	themes         = make(map[string]*iconTheme)
+	iconsMu        sync.Mutex
+	currentTheme   = ""
+	themeCheckedAt time.Time
+)
+
+// LookupIcon resolves a themed icon name to a file path, following the
+// freedesktop icon theme spec: the current theme and its parents, then
+// hicolor and finally the pixmaps dirs. Results are cached until the theme changes.
+func LookupIcon(name string, size int) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+
+	if filepath.IsAbs(name) {
+		return name, FileExists(name)
+	}
+
+	iconsMu.Lock()
+	defer iconsMu.Unlock()
+
+	if time.Since(themeCheckedAt) > iconThemeCheckInterval {
+		themeCheckedAt = time.Now()
+
+		if theme := currentIconTheme(); theme != currentTheme {
+			currentTheme = theme
+			clear(icons)
+			clear(themes)
+		}
+	}
+
+	key := name + ":" + strconv.Itoa(size)
+
+	if path, ok := icons[key]; ok {
+		return path, path != ""
+	}
+
+	path := findIcon(name, size, currentTheme, map[string]bool{})
+
+	if path == "" && currentTheme != "hicolor" {
+		path = findIcon(name, size, "hicolor", map[string]bool{})
+	}
+
+	if path == "" {
+		path = findPixmap(name)
+	}
+
+	icons[key] = path
+
+	return path, path != ""
+}
+
+func iconBaseDirs() []string {
+	res := []string{filepath.Join(xdg.Home, ".icons")}
+
+	for _, v := range append([]string{xdg.DataHome}, xdg.DataDirs...) {
+		res = append(res, filepath.Join(v, "icons"))
+	}
+
+	return append(res, "/usr/share/pixmaps")
+}
+
+func currentIconTheme() string {
+	if cfg := GetElephantConfig(); cfg != nil && cfg.IconTheme != "" {
+		return cfg.IconTheme
+	}
+
+	if out, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "icon-theme").Output(); err == nil {
+		if theme := strings.Trim(strings.TrimSpace(string(out)), "'"); theme != "" {
+			return theme
+		}
+	}
+
+	for _, v := range []string{"gtk-4.0", "gtk-3.0"} {
+		f, err := os.Open(filepath.Join(xdg.ConfigHome, v, "settings.ini"))
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(f)
+
+		for scanner.Scan() {
+			key, val, ok := strings.Cut(scanner.Text(), "=")
+			if ok && strings.TrimSpace(key) == "gtk-icon-theme-name" {
+				f.Close()
+				return strings.TrimSpace(val)
+			}
+		}
+
+		f.Close()
+	}
+
+	return "hicolor"
+}
+
+func findIcon(name string, size int, theme string, visited map[string]bool) string {
+	if visited[theme] {
+		return ""
+	}
+
+	visited[theme] = true
+
+	t := loadIconTheme(theme)
+	if t == nil {
+		return ""
+	}
+
+	if path := lookupInTheme(name, size, theme, t); path != "" {
+		return path
+	}
+
+	for _, v := range t.inherits {
+		if path := findIcon(name, size, v, visited); path != "" {
+			return path
+		}
+	}
+
+	return ""
+}
+
+func lookupInTheme(name string, size int, theme string, t *iconTheme) string {
+	best := ""
+	bestDistance := int(^uint(0) >> 1)
+
+	for _, base := range iconBaseDirs() {
+		for _, d := range t.dirs {
+			for _, ext := range iconExtensions {
+				path := filepath.Join(base, theme, d.path, name+ext)
+
+				if !FileExists(path) {
+					continue
+				}
+
+				if d.matches(size) {
+					return path
+				}
+
+				if distance := d.distance(size); distance < bestDistance {
+					best = path
+					bestDistance = distance
+				}
+			}
+		}
+	}
+
+	return best
+}
+
+func (d iconDir) matches(size int) bool {
+	switch d.kind {
+	case "Fixed":
+		return d.size == size
+	case "Scalable":
+		return d.minSize <= size && size <= d.maxSize
+	default:
+		return d.size-d.threshold <= size && size <= d.size+d.threshold
+	}
+}
+
+func (d iconDir) distance(size int) int {
+	s := size * d.scale
+
+	switch d.kind {
+	case "Fixed":
+		return abs(d.size*d.scale - s)
+	case "Scalable":
+		if s < d.minSize*d.scale {
+			return d.minSize*d.scale - s
+		}
+
+		if s > d.maxSize*d.scale {
+			return s - d.maxSize*d.scale
+		}
+	default:
+		if s < (d.size-d.threshold)*d.scale {
+			return d.minSize*d.scale - s
+		}
+
+		if s > (d.size+d.threshold)*d.scale {
+			return s - d.maxSize*d.scale
+		}
+	}
+
+	return 0
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+
+	return i
+}
+
+func findPixmap(name string) string {
+	for _, ext := range iconExtensions {
+		path := filepath.Join("/usr/share/pixmaps", name+ext)
+
+		if FileExists(path) {
+			return path
+		}
+	}
+
+	return ""
+}
+
+func loadIconTheme(theme string) *iconTheme {
+	if t, ok := themes[theme]; ok {
+		return t
+	}
+
+	var t *iconTheme
+
+	for _, base := range iconBaseDirs() {
+		if f := filepath.Join(base, theme, "index.theme"); FileExists(f) {
+			t = parseIconTheme(f)
+			break
+		}
+	}
+
+	themes[theme] = t
+
+	return t
+}
+
+func parseIconTheme(file string) *iconTheme {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	sections := make(map[string]map[string]string)
+	current := ""
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = line[1 : len(line)-1]
+			sections[current] = make(map[string]string)
+			continue
+		}
+
+		if key, val, ok := strings.Cut(line, "="); ok && current != "" {
+			sections[current][strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+	}
+
+	main := sections["Icon Theme"]
+	if main == nil {
+		return nil
+	}
+
+	t := &iconTheme{}
+
+	if v := main["Inherits"]; v != "" {
+		for _, i := range strings.Split(v, ",") {
+			t.inherits = append(t.inherits, strings.TrimSpace(i))
+		}
+	}
+
+	dirs := strings.Split(main["Directories"], ",")
+
+	if v := main["ScaledDirectories"]; v != "" {
+		dirs = append(dirs, strings.Split(v, ",")...)
+	}
+
+	for _, v := range dirs {
+		s, ok := sections[v]
+		if !ok {
+			continue
+		}
+
+		d := iconDir{
+			path:      v,
+			size:      atoi(s["Size"], 0),
+			scale:     atoi(s["Scale"], 1),
+			threshold: atoi(s["Threshold"], 2),
+			kind:      s["Type"],
+		}
+
+		d.minSize = atoi(s["MinSize"], d.size)
+		d.maxSize = atoi(s["MaxSize"], d.size)
+
+		if d.kind == "" {
+			d.kind = "Threshold"
+		}
+
+		t.dirs = append(t.dirs, d)
+	}
+
+	return t
+}
+
+func atoi(s string, fallback int) int {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+
+	return i
+}
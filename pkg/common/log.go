@@ -0,0 +1,145 @@
+package common
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxLastErrors = 10
+
+type LogEntry struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+var (
+	logMut     sync.RWMutex
+	logLevels  = map[string]slog.Level{}
+	lastErrors = map[string][]LogEntry{}
+)
+
+type providerHandler struct {
+	next     slog.Handler
+	fallback slog.Level
+}
+
+// NewLogHandler wraps the given handler so that providers can override the log level.
+// Records below the fallback level are dropped unless the provider's level allows them.
+func NewLogHandler(next slog.Handler, fallback slog.Level) slog.Handler {
+	return &providerHandler{
+		next:     next,
+		fallback: fallback,
+	}
+}
+
+func (h *providerHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= h.fallback {
+		return true
+	}
+
+	logMut.RLock()
+	defer logMut.RUnlock()
+
+	for _, v := range logLevels {
+		if level >= v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *providerHandler) Handle(ctx context.Context, r slog.Record) error {
+	// providers log with their name as message, so it's used to pick the level.
+	logMut.RLock()
+	level, ok := logLevels[r.Message]
+	logMut.RUnlock()
+
+	if !ok {
+		level = h.fallback
+	}
+
+	if r.Level >= slog.LevelError {
+		storeError(r)
+	}
+
+	if r.Level < level {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *providerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &providerHandler{next: h.next.WithAttrs(attrs), fallback: h.fallback}
+}
+
+func (h *providerHandler) WithGroup(name string) slog.Handler {
+	return &providerHandler{next: h.next.WithGroup(name), fallback: h.fallback}
+}
+
+func storeError(r slog.Record) {
+	attrs := []string{}
+
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a.String())
+		return true
+	})
+
+	logMut.Lock()
+	defer logMut.Unlock()
+
+	entries := append(lastErrors[r.Message], LogEntry{
+		Time:    r.Time,
+		Message: strings.Join(attrs, " "),
+	})
+
+	if len(entries) > maxLastErrors {
+		entries = entries[len(entries)-maxLastErrors:]
+	}
+
+	lastErrors[r.Message] = entries
+}
+
+// SetLogLevel sets the log level for the given provider. An empty level resets it.
+func SetLogLevel(provider, level string) error {
+	logMut.Lock()
+	defer logMut.Unlock()
+
+	if level == "" {
+		delete(logLevels, provider)
+		return nil
+	}
+
+	var l slog.Level
+
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+
+	logLevels[provider] = l
+
+	return nil
+}
+
+func LogLevel(provider string) string {
+	logMut.RLock()
+	defer logMut.RUnlock()
+
+	if l, ok := logLevels[provider]; ok {
+		return strings.ToLower(l.String())
+	}
+
+	return ""
+}
+
+// LastErrors returns the most recent errors logged by the given provider.
+func LastErrors(provider string) []LogEntry {
+	logMut.RLock()
+	defer logMut.RUnlock()
+
+	return append([]LogEntry{}, lastErrors[provider]...)
+}
@@ -0,0 +1,154 @@
+package common
+
+import (
+	"regexp"
+	"slices"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+type MatchMode int
+
+const (
+	MatchFuzzy MatchMode = iota
+	MatchExact
+	MatchPrefix
+	MatchRegex
+)
+
+// scores for prefix and regex matches, mirroring fzf's exact match scoring
+const (
+	matchScorePerRune = 16
+	matchBonusStart   = 20
+	maxCachedRegexes  = 64
+)
+
+var (
+	regexes   = make(map[string]*regexp.Regexp)
+	regexesMu sync.Mutex
+)
+
+// ParseMatchMode detects the match mode from the query. "'foo" forces an
+// exact match like in fzf, "^foo" matches targets starting with "foo", "/re/"
+// matches the regular expression "re". Everything else is matched fuzzy.
+func ParseMatchMode(query string) (string, MatchMode) {
+	return parseMatchMode(query, true)
+}
+
+// ParsePathMatchMode is ParseMatchMode for queries that are paths, "/etc/" is
+// matched as is instead of as a regular expression.
+func ParsePathMatchMode(query string) (string, MatchMode) {
+	return parseMatchMode(query, false)
+}
+
+func parseMatchMode(query string, regex bool) (string, MatchMode) {
+	switch {
+	case len(query) > 1 && query[0] == '\'':
+		return query[1:], MatchExact
+	case len(query) > 1 && query[0] == '^':
+		return query[1:], MatchPrefix
+	case regex && len(query) > 2 && query[0] == '/' && query[len(query)-1] == '/':
+		return query[1 : len(query)-1], MatchRegex
+	}
+
+	return query, MatchFuzzy
+}
+
+// Match scores text against query using the given mode. It returns the score,
+// the matched positions and the start of the match as rune indices. Invalid
+// regular expressions are matched literally.
+func Match(query, text string, mode MatchMode) (int32, []int32, int32) {
+	switch mode {
+	case MatchExact:
+		return fzfScore(query, text, true)
+	case MatchPrefix:
+		return prefixScore(query, text)
+	case MatchRegex:
+		re := compileRegex(query)
+		if re == nil {
+			return fzfScore(query, text, true)
+		}
+
+		return regexScore(re, text)
+	default:
+		return fzfScore(query, text, false)
+	}
+}
+
+func prefixScore(query, text string) (int32, []int32, int32) {
+	q := fold(query)
+	t := fold(text)
+
+	if !slices.ContainsFunc(q, unicode.IsUpper) {
+		for i, r := range t {
+			t[i] = unicode.ToLower(r)
+		}
+	}
+
+	if len(q) > len(t) || !slices.Equal(q, t[:len(q)]) {
+		return 0, []int32{}, -1
+	}
+
+	return matchResult(0, len(q))
+}
+
+func regexScore(re *regexp.Regexp, text string) (int32, []int32, int32) {
+	folded := string(fold(text))
+
+	loc := re.FindStringIndex(folded)
+	if loc == nil || loc[0] == loc[1] {
+		return 0, []int32{}, -1
+	}
+
+	start := utf8.RuneCountInString(folded[:loc[0]])
+
+	return matchResult(start, utf8.RuneCountInString(folded[loc[0]:loc[1]]))
+}
+
+func matchResult(start, length int) (int32, []int32, int32) {
+	positions := make([]int32, length)
+
+	// descending, like fzf returns them
+	for i := range positions {
+		positions[i] = int32(start + length - 1 - i)
+	}
+
+	score := length*matchScorePerRune - start
+
+	if start == 0 {
+		score += matchBonusStart
+	}
+
+	return int32(score), positions, int32(start)
+}
+
+// compileRegex returns nil for invalid expressions. Regexes are matched case
+// insensitive unless they contain upper case characters.
+func compileRegex(expr string) *regexp.Regexp {
+	regexesMu.Lock()
+	defer regexesMu.Unlock()
+
+	if re, ok := regexes[expr]; ok {
+		return re
+	}
+
+	if len(regexes) >= maxCachedRegexes {
+		clear(regexes)
+	}
+
+	pattern := string(fold(expr))
+
+	if !slices.ContainsFunc([]rune(pattern), unicode.IsUpper) {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+
+	regexes[expr] = re
+
+	return re
+}
@@ -0,0 +1,149 @@
+package common
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMatchMode(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		mode  MatchMode
+		path  MatchMode
+	}{
+		{"fire", "fire", MatchFuzzy, MatchFuzzy},
+		{"'fire", "fire", MatchExact, MatchExact},
+		{"'^fire", "^fire", MatchExact, MatchExact},
+		{"'", "'", MatchFuzzy, MatchFuzzy},
+		{"^fire", "fire", MatchPrefix, MatchPrefix},
+		{"^", "^", MatchFuzzy, MatchFuzzy},
+		{"/fi.e/", "fi.e", MatchRegex, MatchFuzzy},
+		{"/etc/", "etc", MatchRegex, MatchFuzzy},
+		{"//", "//", MatchFuzzy, MatchFuzzy},
+		{"/etc", "/etc", MatchFuzzy, MatchFuzzy},
+	}
+
+	for _, tt := range tests {
+		if got, mode := ParseMatchMode(tt.query); got != tt.want || mode != tt.mode {
+			t.Errorf("%q: got %q (%d), want %q (%d)", tt.query, got, mode, tt.want, tt.mode)
+		}
+
+		got, mode := ParsePathMatchMode(tt.query)
+
+		if tt.path == MatchFuzzy && got != tt.query || mode != tt.path {
+			t.Errorf("%q as path: got %q (%d), want mode %d", tt.query, got, mode, tt.path)
+		}
+	}
+}
+
+func TestMatchPrefix(t *testing.T) {
+	tests := []struct {
+		query     string
+		text      string
+		positions []int32
+	}{
+		{"fire", "Firefox", []int32{3, 2, 1, 0}},
+		{"über", "Uber Eats", []int32{3, 2, 1, 0}},
+		{"Fire", "Firefox", []int32{3, 2, 1, 0}},
+		{"fox", "Firefox", nil},
+		{"Fire", "firefox", nil},
+		{"firefox nightly", "Firefox", nil},
+	}
+
+	for _, tt := range tests {
+		score, positions, start := Match(tt.query, tt.text, MatchPrefix)
+
+		if tt.positions == nil {
+			if score != 0 || start != -1 {
+				t.Errorf("%q in %q: got score %d, want no match", tt.query, tt.text, score)
+			}
+
+			continue
+		}
+
+		if score <= 0 || start != 0 || !slices.Equal(positions, tt.positions) {
+			t.Errorf("%q in %q: got %d %v %d, want positions %v", tt.query, tt.text, score, positions, start, tt.positions)
+		}
+	}
+}
+
+func TestMatchRegex(t *testing.T) {
+	tests := []struct {
+		query     string
+		text      string
+		start     int32
+		positions []int32
+	}{
+		{"fi.e", "Firefox", 0, []int32{3, 2, 1, 0}},
+		{"fox$", "Firefox", 4, []int32{6, 5, 4}},
+		{"^https?:", "https://example.com", 0, []int32{5, 4, 3, 2, 1, 0}},
+		{"ü", "Müller", 1, []int32{1}},
+		{"Fox", "Firefox", -1, nil},
+		{"^fox", "Firefox", -1, nil},
+		{"x*", "abc", -1, nil},
+	}
+
+	for _, tt := range tests {
+		score, positions, start := Match(tt.query, tt.text, MatchRegex)
+
+		if start != tt.start || !slices.Equal(positions, tt.positions) && tt.positions != nil {
+			t.Errorf("%q in %q: got %v %d, want %v %d", tt.query, tt.text, positions, start, tt.positions, tt.start)
+		}
+
+		if (score > 0) != (tt.positions != nil) {
+			t.Errorf("%q in %q: got score %d", tt.query, tt.text, score)
+		}
+	}
+
+	early, _, _ := Match("fox", "Fox Terrier", MatchRegex)
+	late, _, _ := Match("fox", "Firefox", MatchRegex)
+
+	if early <= late {
+		t.Errorf("match at the start (%d) doesn't outrank later match (%d)", early, late)
+	}
+}
+
+func TestMatchInvalidRegex(t *testing.T) {
+	score, positions, start := Match("a(b", "xa(bx", MatchRegex)
+
+	if score <= 0 || start != 1 || !slices.Equal(positions, []int32{3, 2, 1}) {
+		t.Errorf("got %d %v %d, want a literal match", score, positions, start)
+	}
+
+	if _, _, start := Match("a(b", "ab", MatchRegex); start != -1 {
+		t.Errorf("got a match at %d for a fuzzy match of an invalid regex", start)
+	}
+}
+
+func TestFuzzyScorePath(t *testing.T) {
+	if score, _, _ := FuzzyScore("/etc/", "/etc/hosts", false); score == 0 {
+		t.Error("/etc/ didn't match as a regex")
+	}
+
+	score, positions, start := FuzzyScorePath("/etc/", "/etc/hosts", false)
+
+	if score == 0 || start != 0 || !slices.Equal(positions, []int32{4, 3, 2, 1, 0}) {
+		t.Errorf("got %d %v %d, want /etc/ matched as path", score, positions, start)
+	}
+
+	if _, _, start := FuzzyScorePath("/etc/", "/home/etc", true); start != -1 {
+		t.Errorf("got a match at %d, /etc/ was matched as a regex", start)
+	}
+}
+
+func TestFuzzyScoreExactPrefix(t *testing.T) {
+	if _, _, start := FuzzyScore("ffx", "Firefox", false); start == -1 {
+		t.Error("ffx didn't match fuzzy")
+	}
+
+	if _, _, start := FuzzyScore("'ffx", "Firefox", false); start != -1 {
+		t.Error("'ffx matched fuzzy")
+	}
+
+	_, positions, start := FuzzyScore("'fox", "Firefox", false)
+
+	if start != 4 || !slices.Equal(positions, []int32{6, 5, 4}) {
+		t.Errorf("got %v %d, want an exact match of fox", positions, start)
+	}
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
@@ -8,8 +9,11 @@ import (
 	"io/fs"
 	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/charlievieth/fastwalk"
@@ -25,7 +29,7 @@ var (
 
 type MenuConfig struct {
 	Config `koanf:",squash"`
-	Paths  []string `koanf:"paths" desc:"additional paths to check for menu definitions." default:""`
+	Paths  []string `koanf:"paths" desc:"additional paths to check for menu definitions." default:"" expand:"true"`
 }
 
 type Menu struct {
@@ -48,10 +52,15 @@ type Menu struct {
 	MinScore             int32             `toml:"min_score" desc:"minimum score for items to be displayed" default:"depends on provider"`
 	Parent               string            `toml:"parent" desc:"defines the parent menu" default:""`
 	SubMenu              string            `toml:"submenu" desc:"defines submenu to trigger on activation" default:""`
+	FlattenSearch        bool              `toml:"flatten_search" desc:"searching the menu also searches all its submenus" default:"false"`
 
 	// internal
-	LuaString string
-	IsLua     bool `toml:"-"`
+	LuaString  string
+	IsLua      bool   `toml:"-"`
+	SourceExec string `toml:"-"`
+	ActionExec string `toml:"-"`
+	CacheTTL   int    `toml:"-"`
+	loadedAt   time.Time
 }
 
 func (m *Menu) NewLuaState() *lua.LState {
@@ -325,6 +334,61 @@ func (m *Menu) CreateLuaEntries() {
 	m.Entries = res
 }
 
+// SourceCommand creates the entries of a submenu from the output of a command.
+const SourceCommand = "command"
+
+// max time a source command can take
+const sourceTimeout = 5 * time.Second
+
+// CommandEntriesExpired reports if the entries created by SourceExec have to be created again.
+func (m *Menu) CommandEntriesExpired() bool {
+	return m.SourceExec != "" && (m.loadedAt.IsZero() || time.Since(m.loadedAt) >= time.Duration(m.CacheTTL)*time.Second)
+}
+
+// CreateCommandEntries runs SourceExec and creates an entry for each line of its output.
+func (m *Menu) CreateCommandEntries() {
+	ctx, cancel := context.WithTimeout(context.Background(), sourceTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "sh", "-c", m.SourceExec).Output()
+	if err != nil {
+		slog.Error(m.Name, "CreateCommandEntries", err)
+		return
+	}
+
+	res := []Entry{}
+
+	for line := range strings.Lines(string(out)) {
+		fields := strings.Split(strings.TrimRight(line, "\r\n"), "\t")
+
+		if fields[0] == "" {
+			continue
+		}
+
+		entry := Entry{
+			Text:  fields[0],
+			Value: fields[0],
+			Exec:  m.ActionExec,
+			Menu:  m.Name,
+		}
+
+		if len(fields) > 1 {
+			entry.Icon = fields[1]
+		}
+
+		if len(fields) > 2 {
+			entry.Value = fields[2]
+		}
+
+		entry.Identifier = fmt.Sprintf("%s:%s", m.Name, entry.CreateIdentifier())
+
+		res = append(res, entry)
+	}
+
+	m.Entries = res
+	m.loadedAt = time.Now()
+}
+
 type Entry struct {
 	Text        string            `toml:"text" desc:"text for entry"`
 	Async       string            `toml:"async" desc:"if the text should be updated asynchronously based on the action"`
@@ -338,6 +402,12 @@ type Entry struct {
 	PreviewType string            `toml:"preview_type" desc:"type of the preview: text, file [default], command"`
 	Keywords    []string          `toml:"keywords" desc:"searchable keywords"`
 	State       []string          `toml:"state" desc:"state of an item, can be used to f.e. mark it as current"`
+	Exec        string            `toml:"exec" desc:"command to run if none of the actions match"`
+	Confirm     bool              `toml:"confirm" desc:"has to be activated twice, the first activation asks for confirmation"`
+	Entries     []Entry           `toml:"entries" desc:"child entries, opened as submenu. can be nested"`
+	Source      string            `toml:"source" desc:"set to 'command' to create the child entries from the output of exec. one entry per line: text, optionally followed by tab separated icon and value"`
+	ActionExec  string            `toml:"action_exec" desc:"command to run for entries created by source, supports %VALUE%"`
+	CacheTTL    int               `toml:"cache_ttl" desc:"seconds to keep the entries created by source, 0 runs exec on every query" default:"0"`
 
 	Identifier string `toml:"-"`
 	Menu       string `toml:"-"`
@@ -528,6 +598,43 @@ func createTomlMenu(path string) {
 		slog.Error(menuname, "setup", err)
 	}
 
+	addTomlMenu(&m)
+}
+
+// addTomlMenu registers the menu. Entries with child entries get a generated
+// submenu, which inherits the settings of the menu.
+func addTomlMenu(m *Menu) {
+	for k, v := range m.Entries {
+		if (len(v.Entries) == 0 && v.Source != SourceCommand) || v.SubMenu != "" {
+			continue
+		}
+
+		child := *m
+		child.Name = fmt.Sprintf("%s/%d", m.Name, k)
+		child.NamePretty = v.Text
+		child.Description = v.Subtext
+		child.Parent = m.Name
+		child.SubMenu = ""
+		child.Keywords = nil
+		child.HideFromProviderlist = true
+		child.Entries = v.Entries
+
+		if v.Source == SourceCommand {
+			child.Entries = nil
+			child.SourceExec = v.Exec
+			child.ActionExec = v.ActionExec
+			child.CacheTTL = v.CacheTTL
+		}
+
+		if v.Icon != "" {
+			child.Icon = v.Icon
+		}
+
+		m.Entries[k].SubMenu = child.Name
+
+		addTomlMenu(&child)
+	}
+
 	for k, v := range m.Entries {
 		m.Entries[k].Menu = m.Name
 		identifier := m.Entries[k].CreateIdentifier()
@@ -541,5 +648,27 @@ func createTomlMenu(path string) {
 		}
 	}
 
-	Menus[m.Name] = &m
+	Menus[m.Name] = m
+}
+
+// IsSubMenuOf reports if the menu is a direct or nested submenu of parent.
+func (m *Menu) IsSubMenuOf(parent string) bool {
+	seen := map[string]bool{}
+
+	for p := m.Parent; p != "" && !seen[p]; {
+		if p == parent {
+			return true
+		}
+
+		seen[p] = true
+
+		val, ok := Menus[p]
+		if !ok {
+			return false
+		}
+
+		p = val.Parent
+	}
+
+	return false
 }
@@ -0,0 +1,104 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	previewTimeout   = 5 * time.Second
+	previewMaxOutput = 512 * 1024
+	previewChunkSize = 32 * 1024
+)
+
+// RunPreview runs a preview command and passes its stdout to out in chunks.
+// The command has no stdin, runs in its own process group in the temp dir and
+// is killed after a timeout or once it wrote too much.
+func RunPreview(ctx context.Context, command string, out func(string) error) error {
+	ctx, cancel := context.WithTimeout(ctx, previewTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = os.TempDir()
+	cmd.Env = launchEnv()
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	buf := make([]byte, previewChunkSize)
+	rest := []byte{}
+	total := 0
+
+	for total < previewMaxOutput {
+		n, rerr := stdout.Read(buf)
+		total += n
+
+		chunk := append(rest, buf[:n]...)
+		rest = []byte{}
+
+		// keep runes split between reads for the next chunk
+		if rerr == nil {
+			i := len(chunk)
+			for i > 0 && len(chunk)-i < utf8.UTFMax && !utf8.Valid(chunk[:i]) {
+				i--
+			}
+
+			if i > 0 {
+				rest = append(rest, chunk[i:]...)
+				chunk = chunk[:i]
+			}
+		}
+
+		if len(chunk) > 0 {
+			if err := out(strings.ToValidUTF8(string(chunk), "�")); err != nil {
+				cancel()
+				cmd.Wait()
+
+				return err
+			}
+		}
+
+		if rerr != nil {
+			if !errors.Is(rerr, io.EOF) {
+				cancel()
+			}
+
+			break
+		}
+	}
+
+	if total >= previewMaxOutput {
+		cancel()
+	}
+
+	err = cmd.Wait()
+	if err != nil && ctx.Err() != nil {
+		// killed on purpose, the output so far is still valid
+		if total >= previewMaxOutput {
+			return nil
+		}
+
+		return ctx.Err()
+	}
+
+	return err
+}
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// size of generated qr codes in pixels
+const qrSize = 512
+
+// QRCode renders content as qr code png in the cache dir and returns its path.
+// Content exceeding the capacity of a qr code returns an error instead of a truncated code.
+func QRCode(content string) (string, error) {
+	if content == "" {
+		return "", fmt.Errorf("qr code: no content")
+	}
+
+	b, err := qrcode.Encode(content, qrcode.Medium, qrSize)
+	if err != nil {
+		return "", fmt.Errorf("qr code: %w", err)
+	}
+
+	sum := md5.Sum([]byte(content))
+	file := CacheFile(filepath.Join("qr", hex.EncodeToString(sum[:])+".png"))
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(file, b, 0o600); err != nil {
+		return "", err
+	}
+
+	return file, nil
+}
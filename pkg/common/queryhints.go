@@ -0,0 +1,37 @@
+package common
+
+import (
+	"sync"
+	"unicode/utf8"
+)
+
+// QueryHints tell the query handler and frontends how often to query a provider.
+type QueryHints struct {
+	MinQueryLength int
+	DebounceMs     int
+}
+
+var (
+	queryHintsMut sync.RWMutex
+	queryHints    = map[string]QueryHints{}
+)
+
+func setQueryHints(provider string, hints QueryHints) {
+	queryHintsMut.Lock()
+	defer queryHintsMut.Unlock()
+
+	queryHints[provider] = hints
+}
+
+// GetQueryHints returns the query hints of the given provider.
+func GetQueryHints(provider string) QueryHints {
+	queryHintsMut.RLock()
+	defer queryHintsMut.RUnlock()
+
+	return queryHints[provider]
+}
+
+// TooShort reports if the query is below the provider's min_query_length.
+func (h QueryHints) TooShort(query string) bool {
+	return query != "" && utf8.RuneCountInString(query) < h.MinQueryLength
+}
@@ -0,0 +1,106 @@
+package common
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+const systemdRunPrefix = "systemd-run --user --scope"
+
+// env vars describing elephant's own process or service, which shouldn't leak into launched programs
+var launchEnvBlacklist = []string{
+	"INVOCATION_ID",
+	"JOURNAL_STREAM",
+	"NOTIFY_SOCKET",
+	"LISTEN_PID",
+	"LISTEN_FDS",
+	"LISTEN_FDNAMES",
+	"WATCHDOG_USEC",
+	"WATCHDOG_PID",
+	"MANAGERPID",
+	"SYSTEMD_EXEC_PID",
+}
+
+type RunOpts struct {
+	// Command is run with `sh -c`.
+	Command string
+	// Prefix overrides the launch prefix, see LaunchPrefix.
+	Prefix string
+	// NoPrefix runs the command without launch prefix. Prefixes like app2unit
+	// return right away, this keeps OnExit bound to the command itself.
+	NoPrefix bool
+	// Terminal wraps the command with the terminal before the prefix is applied,
+	// unless the command already starts a terminal.
+	Terminal bool
+	// TerminalCmd overrides the detected terminal, see WrapWithTerminalCmd.
+	TerminalCmd string
+	Dir         string
+	Stdin       io.Reader
+	// OnExit is called once the command exited.
+	OnExit func(err error)
+}
+
+// RunDetached starts a command in its own session with stdout and stderr
+// detached, so it keeps running independently of elephant. Returns the pid.
+func RunDetached(opts RunOpts) (int, error) {
+	run := opts.Command
+
+	if opts.Terminal && !StartsWithTerminal(opts.TerminalCmd, run) {
+		run = WrapWithTerminalCmd(opts.TerminalCmd, run)
+	}
+
+	prefix := ""
+
+	if !opts.NoPrefix {
+		prefix = LaunchPrefix(opts.Prefix)
+
+		if opts.Prefix == "" && elephantConfig != nil && elephantConfig.PreferSystemdRun {
+			if _, err := exec.LookPath("systemd-run"); err == nil {
+				prefix = systemdRunPrefix
+			}
+		}
+	}
+
+	cmd := exec.Command("sh", "-c", strings.TrimSpace(prefix+" "+run))
+	cmd.Dir = opts.Dir
+	cmd.Stdin = opts.Stdin
+	cmd.Env = launchEnv()
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setsid: true,
+	}
+
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	go func() {
+		err := cmd.Wait()
+
+		if opts.OnExit != nil {
+			opts.OnExit(err)
+		}
+	}()
+
+	return cmd.Process.Pid, nil
+}
+
+func launchEnv() []string {
+	env := []string{}
+
+	for _, v := range os.Environ() {
+		name, _, _ := strings.Cut(v, "=")
+
+		if strings.HasPrefix(name, "ELEPHANT_") || slices.Contains(launchEnvBlacklist, name) {
+			continue
+		}
+
+		env = append(env, v)
+	}
+
+	return env
+}
@@ -0,0 +1,177 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runWait runs the command detached and waits until it exited.
+func runWait(t *testing.T, opts RunOpts) (int, error) {
+	t.Helper()
+
+	exited := make(chan error, 1)
+
+	opts.OnExit = func(err error) {
+		exited <- err
+	}
+
+	pid, err := RunDetached(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-exited:
+		return pid, err
+	case <-time.After(5 * time.Second):
+		t.Fatal("command didn't exit")
+	}
+
+	return pid, nil
+}
+
+func readOutput(t *testing.T, file string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.TrimSpace(string(b))
+}
+
+func TestRunDetachedExit(t *testing.T) {
+	if _, err := runWait(t, RunOpts{Command: "true"}); err != nil {
+		t.Errorf("got %v, want no error", err)
+	}
+
+	if _, err := runWait(t, RunOpts{Command: "exit 3"}); err == nil {
+		t.Error("got no error for a failing command")
+	}
+
+	if _, err := RunDetached(RunOpts{Command: "true", Dir: "/does/not/exist"}); err == nil {
+		t.Error("got no error for a missing directory")
+	}
+}
+
+func TestRunDetachedSession(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+
+	// the 6th field of /proc/<pid>/stat is the session id
+	pid, err := runWait(t, RunOpts{Command: "cut -d' ' -f6 /proc/$$/stat > " + out})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readOutput(t, out); got != strconv.Itoa(pid) {
+		t.Errorf("session %s, want a new session %d", got, pid)
+	}
+}
+
+func TestRunDetachedEnv(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+
+	t.Setenv("ELEPHANT_TEST", "1")
+	t.Setenv("INVOCATION_ID", "abc")
+	t.Setenv("NOTIFY_SOCKET", "/run/notify")
+	t.Setenv("KEEP_ME", "1")
+
+	if _, err := runWait(t, RunOpts{Command: "env > " + out}); err != nil {
+		t.Fatal(err)
+	}
+
+	env := strings.Split(readOutput(t, out), "\n")
+
+	for _, v := range env {
+		name, _, _ := strings.Cut(v, "=")
+
+		if name == "ELEPHANT_TEST" || name == "INVOCATION_ID" || name == "NOTIFY_SOCKET" {
+			t.Errorf("%s leaked into the command", name)
+		}
+	}
+
+	if !strings.Contains(readOutput(t, out), "KEEP_ME=1") {
+		t.Error("KEEP_ME was removed")
+	}
+}
+
+func TestRunDetachedOpts(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+
+	_, err := runWait(t, RunOpts{
+		Command: "sh -c 'read -r line; echo \"$PREFIXED $line $(pwd)\" > " + out + "'",
+		Prefix:  "env PREFIXED=yes",
+		Dir:     dir,
+		Stdin:   strings.NewReader("input\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := readOutput(t, out), "yes input "+dir; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRunDetachedNoPrefix(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+
+	prev := runPrefix
+	runPrefix = "env PREFIXED=yes"
+
+	t.Cleanup(func() {
+		runPrefix = prev
+	})
+
+	for _, noPrefix := range []bool{false, true} {
+		if _, err := runWait(t, RunOpts{Command: "sh -c 'echo \"$PREFIXED\" > " + out + "'", NoPrefix: noPrefix}); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "yes"
+		if noPrefix {
+			want = ""
+		}
+
+		if got := readOutput(t, out); got != want {
+			t.Errorf("NoPrefix %t: got %q, want %q", noPrefix, got, want)
+		}
+	}
+}
+
+func TestRunDetachedTerminal(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	term := filepath.Join(dir, "fake-term")
+
+	if err := os.WriteFile(term, []byte("#!/bin/sh\necho \"$@\" >> "+out+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"htop", "-e htop"},
+		// already started in the terminal, not wrapped twice
+		{term + " -e top", "-e top"},
+	}
+
+	for _, tt := range tests {
+		os.Remove(out)
+
+		if _, err := runWait(t, RunOpts{Command: tt.command, Terminal: true, TerminalCmd: term}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := readOutput(t, out); got != tt.want {
+			t.Errorf("%s: terminal got %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package common
+
+import "sync"
+
+// MaxScore is the upper bound of normalized scores.
+const MaxScore = 1000
+
+// scores up to this are kept as they are, which covers most fuzzy matches.
+const linearScores = MaxScore / 2
+
+var (
+	weightsMut sync.RWMutex
+	weights    = map[string]float64{}
+)
+
+// NormalizeScore maps a provider score to 0-MaxScore, so results of different
+// providers can be compared. Low scores are kept, higher ones, like the
+// synthetic scores used to order empty queries, are compressed into the upper
+// half. The order of scores is kept, but large scores close to each other can
+// end up equal.
+func NormalizeScore(score int32) int32 {
+	if score <= 0 {
+		return 0
+	}
+
+	if score <= linearScores {
+		return score
+	}
+
+	return MaxScore - int32(int64(linearScores)*linearScores/int64(score))
+}
+
+func setWeight(provider string, weight float64) {
+	weightsMut.Lock()
+	defer weightsMut.Unlock()
+
+	weights[provider] = weight
+}
+
+// Weight returns the weight applied to the normalized scores of the provider.
+func Weight(provider string) float64 {
+	weightsMut.RLock()
+	defer weightsMut.RUnlock()
+
+	if w, ok := weights[provider]; ok && w > 0 {
+		return w
+	}
+
+	return 1
+}
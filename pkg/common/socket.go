@@ -0,0 +1,38 @@
+package common
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SocketPath returns the configured socket or the default one in XDG_RUNTIME_DIR.
+func SocketPath(cfg *ElephantConfig) string {
+	if cfg != nil && cfg.Socket != "" {
+		return cfg.Socket
+	}
+
+	rd := os.Getenv("XDG_RUNTIME_DIR")
+
+	if rd == "" {
+		slog.Error("socket", "runtimedir", "XDG_RUNTIME_DIR not set. falling back to /tmp")
+		return filepath.Join(os.TempDir(), "elephant", "elephant.sock")
+	}
+
+	return filepath.Join(rd, "elephant", "elephant.sock")
+}
+
+// SocketSecret reads the shared secret clients have to send. Empty if not configured.
+func SocketSecret(cfg *ElephantConfig) (string, error) {
+	if cfg == nil || cfg.SocketSecretFile == "" {
+		return "", nil
+	}
+
+	b, err := os.ReadFile(cfg.SocketSecretFile)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
 
@@ -20,6 +21,50 @@ var terminal = ""
 
 var terminalApps = make(map[string]struct{})
 
+var knownTerminals = []string{
+	"kitty",
+	"foot",
+	"ghostty",
+	"alacritty",
+	"Eterm",
+	"aterm",
+	"gnome-terminal",
+	"guake",
+	"hyper",
+	"konsole",
+	"lilyterm",
+	"lxterminal",
+	"mate-terminal",
+	"qterminal",
+	"roxterm",
+	"rxvt",
+	"st",
+	"terminator",
+	"terminix",
+	"terminology",
+	"termit",
+	"termite",
+	"tilda",
+	"tilix",
+	"urxvt",
+	"uxterm",
+	"wezterm",
+	"x-terminal-emulator",
+	"xfce4-terminal",
+	"xterm",
+}
+
+// terminalExecFlags are the flags used to run a command, for terminals not using "-e".
+var terminalExecFlags = map[string]string{
+	"foot":           "",
+	"kitty":          "",
+	"gnome-terminal": "--",
+	"wezterm":        "start --",
+}
+
+// flags after which the terminal expects the command
+var execFlags = []string{"-e", "-x", "--", "--command", "--execute"}
+
 func init() {
 	terminal = GetTerminal()
 	findTerminalApps()
@@ -39,40 +84,7 @@ func GetTerminal() string {
 		}
 	}
 
-	t := []string{
-		"kitty",
-		"foot",
-		"ghostty",
-		"alacritty",
-		"Eterm",
-		"aterm",
-		"gnome-terminal",
-		"guake",
-		"hyper",
-		"konsole",
-		"lilyterm",
-		"lxterminal",
-		"mate-terminal",
-		"qterminal",
-		"roxterm",
-		"rxvt",
-		"st",
-		"terminator",
-		"terminix",
-		"terminology",
-		"termit",
-		"termite",
-		"tilda",
-		"tilix",
-		"urxvt",
-		"uxterm",
-		"wezterm",
-		"x-terminal-emulator",
-		"xfce4-terminal",
-		"xterm",
-	}
-
-	for _, v := range t {
+	for _, v := range knownTerminals {
 		path, _ := exec.LookPath(v)
 
 		if path != "" {
@@ -84,11 +96,58 @@ func GetTerminal() string {
 }
 
 func WrapWithTerminal(in string) string {
-	if terminal == "" {
+	return WrapWithTerminalCmd("", in)
+}
+
+// WrapWithTerminalCmd wraps the command with the given terminal, f.e. "foot" or "alacritty -e". The
+// flag to execute the command is added depending on the terminal, if it's not given. An empty terminal
+// uses the detected one.
+func WrapWithTerminalCmd(term, in string) string {
+	if term == "" {
+		term = terminal
+	}
+
+	fields := strings.Fields(term)
+
+	if len(fields) == 0 {
 		return in
 	}
 
-	return fmt.Sprintf("%s -e %s", terminal, in)
+	if len(fields) == 1 || !slices.Contains(execFlags, fields[len(fields)-1]) {
+		flag, ok := terminalExecFlags[filepath.Base(fields[0])]
+		if !ok {
+			flag = "-e"
+		}
+
+		if flag != "" {
+			term = fmt.Sprintf("%s %s", term, flag)
+		}
+	}
+
+	return fmt.Sprintf("%s %s", term, in)
+}
+
+// StartsWithTerminal checks if the command already runs in a terminal, f.e. "kitty -e htop".
+func StartsWithTerminal(term, cmd string) bool {
+	fields := strings.Fields(cmd)
+
+	for len(fields) > 0 && (fields[0] == "env" || strings.Contains(fields[0], "=")) {
+		fields = fields[1:]
+	}
+
+	if len(fields) == 0 {
+		return false
+	}
+
+	bin := filepath.Base(fields[0])
+
+	for _, v := range []string{term, terminal} {
+		if f := strings.Fields(v); len(f) > 0 && filepath.Base(f[0]) == bin {
+			return true
+		}
+	}
+
+	return slices.Contains(knownTerminals, bin)
 }
 
 func findTerminalApps() {
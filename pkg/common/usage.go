@@ -0,0 +1,209 @@
+package common
+
+import (
+	"bytes"
+	"encoding/gob"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ActionResetUsage resets the recorded usage of a provider. Handled for every provider.
+const ActionResetUsage = "reset_usage"
+
+const (
+	usageHalfLife   = 7 * 24 * time.Hour
+	usageMaxEntries = 1000
+	usageMaxScore   = 100
+	usageSaveDelay  = 5 * time.Second
+)
+
+type usageEntry struct {
+	Score    float64
+	LastUsed time.Time
+}
+
+var (
+	usage     map[string]map[string]*usageEntry
+	usageMu   sync.Mutex
+	usageOnce sync.Once
+	usageSave = make(chan struct{}, 1)
+)
+
+// decay halves the weight of a usage every usageHalfLife.
+func decay(since time.Duration) float64 {
+	return math.Exp2(-since.Hours() / usageHalfLife.Hours())
+}
+
+func (e *usageEntry) current(now time.Time) float64 {
+	return e.Score * decay(now.Sub(e.LastUsed))
+}
+
+// RecordUsage adds a usage for the given item, shared across all providers.
+// Providers call it when an item is launched or selected, not for actions
+// like removing or pinning it.
+func RecordUsage(provider, identifier string) {
+	if provider == "" || identifier == "" {
+		return
+	}
+
+	usageOnce.Do(loadUsage)
+
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	now := time.Now()
+
+	if _, ok := usage[provider]; !ok {
+		usage[provider] = make(map[string]*usageEntry)
+	}
+
+	if e, ok := usage[provider][identifier]; ok {
+		e.Score = e.current(now) + 1
+		e.LastUsed = now
+	} else {
+		usage[provider][identifier] = &usageEntry{
+			Score:    1,
+			LastUsed: now,
+		}
+	}
+
+	trimUsage(now)
+	saveUsage()
+}
+
+// UsageScore combines frequency and recency of an item's usage to a score between 0 and 100.
+func UsageScore(provider, identifier string) int32 {
+	usageOnce.Do(loadUsage)
+
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	e, ok := usage[provider][identifier]
+	if !ok {
+		return 0
+	}
+
+	return int32(min(e.current(time.Now())*10, usageMaxScore))
+}
+
+// ResetUsage removes all recorded usage for the provider. An empty provider resets everything.
+func ResetUsage(provider string) {
+	usageOnce.Do(loadUsage)
+
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	if provider == "" {
+		usage = make(map[string]map[string]*usageEntry)
+	} else {
+		delete(usage, provider)
+	}
+
+	saveUsage()
+}
+
+// saveUsage schedules writing the usage, so consecutive changes are written at once.
+func saveUsage() {
+	select {
+	case usageSave <- struct{}{}:
+	default:
+	}
+}
+
+func handleUsageSave() {
+	timer := time.NewTimer(usageSaveDelay)
+	do := false
+
+	for {
+		select {
+		case <-usageSave:
+			timer.Reset(usageSaveDelay)
+			do = true
+		case <-timer.C:
+			if do {
+				usageMu.Lock()
+				writeUsage()
+				usageMu.Unlock()
+
+				do = false
+			}
+		}
+	}
+}
+
+func trimUsage(now time.Time) {
+	amount := 0
+
+	for _, v := range usage {
+		amount += len(v)
+	}
+
+	for amount > usageMaxEntries {
+		var provider, identifier string
+		lowest := math.MaxFloat64
+
+		for p, items := range usage {
+			for i, e := range items {
+				if s := e.current(now); s < lowest {
+					lowest = s
+					provider = p
+					identifier = i
+				}
+			}
+		}
+
+		delete(usage[provider], identifier)
+
+		if len(usage[provider]) == 0 {
+			delete(usage, provider)
+		}
+
+		amount--
+	}
+}
+
+func loadUsage() {
+	usage = make(map[string]map[string]*usageEntry)
+
+	go handleUsageSave()
+
+	file := StateFile("usage.gob")
+
+	if !FileExists(file) {
+		return
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		slog.Error("usage", "load", err)
+		return
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&usage); err != nil {
+		slog.Error("usage", "decoding", err)
+	}
+}
+
+func writeUsage() {
+	var b bytes.Buffer
+
+	if err := gob.NewEncoder(&b).Encode(usage); err != nil {
+		slog.Error("usage", "encode", err)
+		return
+	}
+
+	file := StateFile("usage.gob")
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		slog.Error("usage", "createdirs", err)
+		return
+	}
+
+	if err := os.WriteFile(file, b.Bytes(), 0o600); err != nil {
+		slog.Error("usage", "writefile", err)
+	}
+}
@@ -0,0 +1,128 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// resetUsageStore replaces the store with an empty one, without loading or writing the state file.
+func resetUsageStore(t *testing.T) {
+	t.Helper()
+
+	usageOnce.Do(func() {})
+
+	usageMu.Lock()
+	usage = make(map[string]map[string]*usageEntry)
+	usageMu.Unlock()
+}
+
+func TestDecay(t *testing.T) {
+	tests := []struct {
+		since time.Duration
+		want  float64
+	}{
+		{0, 1},
+		{usageHalfLife / 2, math.Sqrt2 / 2},
+		{usageHalfLife, 0.5},
+		{2 * usageHalfLife, 0.25},
+		{10 * usageHalfLife, 1.0 / 1024},
+	}
+
+	for _, tt := range tests {
+		if got := decay(tt.since); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("decay(%s) = %f, want %f", tt.since, got, tt.want)
+		}
+	}
+
+	prev := decay(0)
+
+	for d := time.Hour; d < 8*usageHalfLife; d += 12 * time.Hour {
+		cur := decay(d)
+
+		if cur >= prev {
+			t.Fatalf("decay(%s) = %f not below %f", d, cur, prev)
+		}
+
+		prev = cur
+	}
+}
+
+func TestUsageScore(t *testing.T) {
+	resetUsageStore(t)
+
+	now := time.Now()
+
+	usage["p"] = map[string]*usageEntry{
+		"fresh":    {Score: 3, LastUsed: now},
+		"halflife": {Score: 3, LastUsed: now.Add(-usageHalfLife)},
+		"old":      {Score: 3, LastUsed: now.Add(-20 * usageHalfLife)},
+		"frequent": {Score: 50, LastUsed: now},
+	}
+
+	tests := []struct {
+		identifier string
+		want       int32
+	}{
+		{"fresh", 30},
+		{"halflife", 15},
+		{"old", 0},
+		{"frequent", usageMaxScore},
+		{"unknown", 0},
+	}
+
+	// time passes between storing and scoring, so the score may already be truncated down by one
+	for _, tt := range tests {
+		if got := UsageScore("p", tt.identifier); got > tt.want || got < tt.want-1 {
+			t.Errorf("%s: got %d, want %d", tt.identifier, got, tt.want)
+		}
+	}
+}
+
+func TestRecordUsageDecays(t *testing.T) {
+	resetUsageStore(t)
+
+	usage["p"] = map[string]*usageEntry{
+		"a": {Score: 4, LastUsed: time.Now().Add(-usageHalfLife)},
+	}
+
+	RecordUsage("p", "a")
+	RecordUsage("p", "b")
+
+	if got := usage["p"]["a"].Score; math.Abs(got-3) > 1e-3 {
+		t.Errorf("a: score %f, want 3", got)
+	}
+
+	if got := usage["p"]["b"].Score; got != 1 {
+		t.Errorf("b: score %f, want 1", got)
+	}
+
+	if UsageScore("p", "a") <= UsageScore("p", "b") {
+		t.Error("frequently used item doesn't outrank a single usage")
+	}
+}
+
+func TestTrimUsage(t *testing.T) {
+	resetUsageStore(t)
+
+	now := time.Now()
+	items := make(map[string]*usageEntry)
+
+	for i := range usageMaxEntries {
+		items[fmt.Sprintf("item-%d", i)] = &usageEntry{Score: 10, LastUsed: now}
+	}
+
+	items["stale"] = &usageEntry{Score: 10, LastUsed: now.Add(-10 * usageHalfLife)}
+	usage["p"] = items
+
+	trimUsage(now)
+
+	if len(usage["p"]) != usageMaxEntries {
+		t.Errorf("got %d entries, want %d", len(usage["p"]), usageMaxEntries)
+	}
+
+	if _, ok := usage["p"]["stale"]; ok {
+		t.Error("least used entry wasn't removed")
+	}
+}
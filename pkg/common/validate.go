@@ -0,0 +1,263 @@
+package common
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	configWarningsMut sync.Mutex
+	configWarnings    = make(map[string][]string)
+)
+
+// ConfigWarnings returns the problems found in the loaded configs, by provider.
+func ConfigWarnings() map[string][]string {
+	configWarningsMut.Lock()
+	defer configWarningsMut.Unlock()
+
+	res := make(map[string][]string, len(configWarnings))
+
+	for k, v := range configWarnings {
+		res[k] = slices.Clone(v)
+	}
+
+	return res
+}
+
+func setConfigWarnings(provider string, warnings []string) {
+	configWarningsMut.Lock()
+	defer configWarningsMut.Unlock()
+
+	if len(warnings) == 0 {
+		delete(configWarnings, provider)
+		return
+	}
+
+	configWarnings[provider] = warnings
+
+	for _, v := range warnings {
+		slog.Warn(provider, "config", v)
+	}
+}
+
+// mapProvider lets koanf load an already parsed config.
+type mapProvider map[string]any
+
+func (m mapProvider) ReadBytes() ([]byte, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (m mapProvider) Read() (map[string]any, error) {
+	return m, nil
+}
+
+// validateConfig checks the user config against the config struct. Unknown
+// keys are reported, values of the wrong type are reported and removed, so the
+// default is used instead.
+func validateConfig(user map[string]any, config any) []string {
+	warnings := []string{}
+	validateValue(user, reflect.TypeOf(config), "", &warnings)
+	slices.Sort(warnings)
+
+	return warnings
+}
+
+// validateValue checks a single value and returns false if it doesn't fit the type.
+func validateValue(value any, t reflect.Type, path string, warnings *[]string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]any)
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("%s: expected a table, got %s", path, describe(value)))
+			return false
+		}
+
+		fields := configFields(t)
+
+		for k, v := range m {
+			p := joinPath(path, k)
+
+			f, ok := fields[k]
+			if !ok {
+				w := fmt.Sprintf("%s: unknown key", p)
+
+				if s := suggest(k, fields); s != "" {
+					w = fmt.Sprintf("%s, did you mean %q?", w, s)
+				}
+
+				*warnings = append(*warnings, w)
+
+				continue
+			}
+
+			if !validateValue(v, f, p, warnings) {
+				delete(m, k)
+			}
+		}
+
+		return true
+	case reflect.Map:
+		m, ok := value.(map[string]any)
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("%s: expected a table, got %s", path, describe(value)))
+			return false
+		}
+
+		for k, v := range m {
+			if !validateValue(v, t.Elem(), joinPath(path, k), warnings) {
+				delete(m, k)
+			}
+		}
+
+		return true
+	case reflect.Slice, reflect.Array:
+		s, ok := value.([]any)
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("%s: expected a list, got %s", path, describe(value)))
+			return false
+		}
+
+		valid := true
+
+		for i, v := range s {
+			if !validateValue(v, t.Elem(), fmt.Sprintf("%s[%d]", path, i), warnings) {
+				valid = false
+			}
+		}
+
+		return valid
+	case reflect.String:
+		return expect[string](value, "a string", path, warnings)
+	case reflect.Bool:
+		return expect[bool](value, "a boolean", path, warnings)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return expect[int64](value, "an integer", path, warnings)
+	case reflect.Float32, reflect.Float64:
+		switch value.(type) {
+		case float64, int64:
+			return true
+		}
+
+		*warnings = append(*warnings, fmt.Sprintf("%s: expected a number, got %s", path, describe(value)))
+
+		return false
+	}
+
+	return true
+}
+
+func expect[T any](value any, name, path string, warnings *[]string) bool {
+	if _, ok := value.(T); ok {
+		return true
+	}
+
+	*warnings = append(*warnings, fmt.Sprintf("%s: expected %s, got %s, using the default", path, name, describe(value)))
+
+	return false
+}
+
+// configFields maps the koanf keys of a struct to their types, including squashed structs.
+func configFields(t reflect.Type) map[string]reflect.Type {
+	res := make(map[string]reflect.Type)
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("koanf")
+
+		if strings.Contains(tag, "squash") {
+			maps := configFields(f.Type)
+
+			for k, v := range maps {
+				res[k] = v
+			}
+
+			continue
+		}
+
+		if tag == "" || tag == "-" || !f.IsExported() {
+			continue
+		}
+
+		res[tag] = f.Type
+	}
+
+	return res
+}
+
+func describe(value any) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case int64:
+		return fmt.Sprintf("integer %d", v)
+	case float64:
+		return fmt.Sprintf("number %v", v)
+	case bool:
+		return fmt.Sprintf("boolean %t", v)
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "a table"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// suggest returns the closest known key, if it's close enough to be a typo.
+func suggest(key string, fields map[string]reflect.Type) string {
+	best := ""
+	bestDist := len(key)/3 + 1
+
+	for k := range fields {
+		if d := levenshtein(key, k); d <= bestDist && (best == "" || d < bestDist || d == bestDist && k < best) {
+			best = k
+			bestDist = d
+		}
+	}
+
+	return best
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestValidateConfigEnabled(t *testing.T) {
+	got := validateConfig(map[string]any{
+		"enabled":   false,
+		"min_score": int64(10),
+		"nested":    map[string]any{"enabled": true},
+	}, &Config{})
+
+	if want := []string{"nested: unknown key"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestProviderEnabled(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "elephant"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	configs := map[string]string{
+		"disabled": "enabled = false",
+		"enabled":  "enabled = true\nmin_score = 10",
+		"unset":    "min_score = 10",
+		"invalid":  `enabled = "no"`,
+	}
+
+	for k, v := range configs {
+		if err := os.WriteFile(filepath.Join(dir, "elephant", k+".toml"), []byte(v), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		provider string
+		want     bool
+	}{
+		{"disabled", false},
+		{"enabled", true},
+		{"unset", true},
+		{"invalid", true},
+		{"missing", true},
+	} {
+		if got := ProviderEnabled(tt.provider); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.provider, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigEnabledDefault(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	config := &struct {
+		Config `koanf:",squash"`
+	}{}
+
+	if err := loadConfig("missing", config); err != nil {
+		t.Fatal(err)
+	}
+
+	if !config.Enabled {
+		t.Error("enabled should default to true")
+	}
+}
@@ -0,0 +1,302 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.32.1
+// source: dmenu.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DmenuRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Items         []*DmenuRequest_Item   `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DmenuRequest) Reset() {
+	*x = DmenuRequest{}
+	mi := &file_dmenu_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DmenuRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DmenuRequest) ProtoMessage() {}
+
+func (x *DmenuRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dmenu_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DmenuRequest.ProtoReflect.Descriptor instead.
+func (*DmenuRequest) Descriptor() ([]byte, []int) {
+	return file_dmenu_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DmenuRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DmenuRequest) GetItems() []*DmenuRequest_Item {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type DmenuResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Identifier    string                 `protobuf:"bytes,2,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	Query         string                 `protobuf:"bytes,5,opt,name=query,proto3" json:"query,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DmenuResponse) Reset() {
+	*x = DmenuResponse{}
+	mi := &file_dmenu_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DmenuResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DmenuResponse) ProtoMessage() {}
+
+func (x *DmenuResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dmenu_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DmenuResponse.ProtoReflect.Descriptor instead.
+func (*DmenuResponse) Descriptor() ([]byte, []int) {
+	return file_dmenu_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DmenuResponse) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *DmenuResponse) GetIdentifier() string {
+	if x != nil {
+		return x.Identifier
+	}
+	return ""
+}
+
+func (x *DmenuResponse) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *DmenuResponse) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *DmenuResponse) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *DmenuResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DmenuRequest_Item struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Identifier    string                 `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Subtext       string                 `protobuf:"bytes,3,opt,name=subtext,proto3" json:"subtext,omitempty"`
+	Icon          string                 `protobuf:"bytes,4,opt,name=icon,proto3" json:"icon,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DmenuRequest_Item) Reset() {
+	*x = DmenuRequest_Item{}
+	mi := &file_dmenu_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DmenuRequest_Item) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DmenuRequest_Item) ProtoMessage() {}
+
+func (x *DmenuRequest_Item) ProtoReflect() protoreflect.Message {
+	mi := &file_dmenu_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DmenuRequest_Item.ProtoReflect.Descriptor instead.
+func (*DmenuRequest_Item) Descriptor() ([]byte, []int) {
+	return file_dmenu_proto_rawDescGZIP(), []int{0, 0}
+}
+
+func (x *DmenuRequest_Item) GetIdentifier() string {
+	if x != nil {
+		return x.Identifier
+	}
+	return ""
+}
+
+func (x *DmenuRequest_Item) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *DmenuRequest_Item) GetSubtext() string {
+	if x != nil {
+		return x.Subtext
+	}
+	return ""
+}
+
+func (x *DmenuRequest_Item) GetIcon() string {
+	if x != nil {
+		return x.Icon
+	}
+	return ""
+}
+
+var File_dmenu_proto protoreflect.FileDescriptor
+
+const file_dmenu_proto_rawDesc = "" +
+	"\n" +
+	"\vdmenu.proto\x12\x02pb\"\xb9\x01\n" +
+	"\fDmenuRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12+\n" +
+	"\x05items\x18\x02 \x03(\v2\x15.pb.DmenuRequest.ItemR\x05items\x1ah\n" +
+	"\x04Item\x12\x1e\n" +
+	"\n" +
+	"identifier\x18\x01 \x01(\tR\n" +
+	"identifier\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x18\n" +
+	"\asubtext\x18\x03 \x01(\tR\asubtext\x12\x12\n" +
+	"\x04icon\x18\x04 \x01(\tR\x04icon\"\xa3\x01\n" +
+	"\rDmenuResponse\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x1e\n" +
+	"\n" +
+	"identifier\x18\x02 \x01(\tR\n" +
+	"identifier\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x14\n" +
+	"\x05query\x18\x05 \x01(\tR\x05query\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05errorB\x06Z\x04./pbb\x06proto3"
+
+var (
+	file_dmenu_proto_rawDescOnce sync.Once
+	file_dmenu_proto_rawDescData []byte
+)
+
+func file_dmenu_proto_rawDescGZIP() []byte {
+	file_dmenu_proto_rawDescOnce.Do(func() {
+		file_dmenu_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_dmenu_proto_rawDesc), len(file_dmenu_proto_rawDesc)))
+	})
+	return file_dmenu_proto_rawDescData
+}
+
+var file_dmenu_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_dmenu_proto_goTypes = []any{
+	(*DmenuRequest)(nil),      // 0: pb.DmenuRequest
+	(*DmenuResponse)(nil),     // 1: pb.DmenuResponse
+	(*DmenuRequest_Item)(nil), // 2: pb.DmenuRequest.Item
+}
+var file_dmenu_proto_depIdxs = []int32{
+	2, // 0: pb.DmenuRequest.items:type_name -> pb.DmenuRequest.Item
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_dmenu_proto_init() }
+func file_dmenu_proto_init() {
+	if File_dmenu_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dmenu_proto_rawDesc), len(file_dmenu_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_dmenu_proto_goTypes,
+		DependencyIndexes: file_dmenu_proto_depIdxs,
+		MessageInfos:      file_dmenu_proto_msgTypes,
+	}.Build()
+	File_dmenu_proto = out.File
+	file_dmenu_proto_goTypes = nil
+	file_dmenu_proto_depIdxs = nil
+}
@@ -66,12 +66,15 @@ func (x *ProviderStateRequest) GetProvider() string {
 }
 
 type ProviderStateResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	States        []string               `protobuf:"bytes,1,rep,name=states,proto3" json:"states,omitempty"`
-	Actions       []string               `protobuf:"bytes,2,rep,name=actions,proto3" json:"actions,omitempty"`
-	Provider      string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	States         []string               `protobuf:"bytes,1,rep,name=states,proto3" json:"states,omitempty"`
+	Actions        []string               `protobuf:"bytes,2,rep,name=actions,proto3" json:"actions,omitempty"`
+	Provider       string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`
+	Stats          *ProviderStats         `protobuf:"bytes,4,opt,name=stats,proto3" json:"stats,omitempty"`
+	MinQueryLength int32                  `protobuf:"varint,5,opt,name=min_query_length,json=minQueryLength,proto3" json:"min_query_length,omitempty"`
+	DebounceMs     int32                  `protobuf:"varint,6,opt,name=debounce_ms,json=debounceMs,proto3" json:"debounce_ms,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ProviderStateResponse) Reset() {
@@ -125,17 +128,124 @@ func (x *ProviderStateResponse) GetProvider() string {
 	return ""
 }
 
+func (x *ProviderStateResponse) GetStats() *ProviderStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *ProviderStateResponse) GetMinQueryLength() int32 {
+	if x != nil {
+		return x.MinQueryLength
+	}
+	return 0
+}
+
+func (x *ProviderStateResponse) GetDebounceMs() int32 {
+	if x != nil {
+		return x.DebounceMs
+	}
+	return 0
+}
+
+type ProviderStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Queries       uint64                 `protobuf:"varint,1,opt,name=queries,proto3" json:"queries,omitempty"`
+	P50Us         int64                  `protobuf:"varint,2,opt,name=p50_us,json=p50Us,proto3" json:"p50_us,omitempty"`
+	P95Us         int64                  `protobuf:"varint,3,opt,name=p95_us,json=p95Us,proto3" json:"p95_us,omitempty"`
+	LastUs        int64                  `protobuf:"varint,4,opt,name=last_us,json=lastUs,proto3" json:"last_us,omitempty"`
+	LastResults   int32                  `protobuf:"varint,5,opt,name=last_results,json=lastResults,proto3" json:"last_results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProviderStats) Reset() {
+	*x = ProviderStats{}
+	mi := &file_providerstate_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProviderStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProviderStats) ProtoMessage() {}
+
+func (x *ProviderStats) ProtoReflect() protoreflect.Message {
+	mi := &file_providerstate_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProviderStats.ProtoReflect.Descriptor instead.
+func (*ProviderStats) Descriptor() ([]byte, []int) {
+	return file_providerstate_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProviderStats) GetQueries() uint64 {
+	if x != nil {
+		return x.Queries
+	}
+	return 0
+}
+
+func (x *ProviderStats) GetP50Us() int64 {
+	if x != nil {
+		return x.P50Us
+	}
+	return 0
+}
+
+func (x *ProviderStats) GetP95Us() int64 {
+	if x != nil {
+		return x.P95Us
+	}
+	return 0
+}
+
+func (x *ProviderStats) GetLastUs() int64 {
+	if x != nil {
+		return x.LastUs
+	}
+	return 0
+}
+
+func (x *ProviderStats) GetLastResults() int32 {
+	if x != nil {
+		return x.LastResults
+	}
+	return 0
+}
+
 var File_providerstate_proto protoreflect.FileDescriptor
 
 const file_providerstate_proto_rawDesc = "" +
 	"\n" +
 	"\x13providerstate.proto\x12\x02pb\"2\n" +
 	"\x14ProviderStateRequest\x12\x1a\n" +
-	"\bprovider\x18\x01 \x01(\tR\bprovider\"e\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\"\xd9\x01\n" +
 	"\x15ProviderStateResponse\x12\x16\n" +
 	"\x06states\x18\x01 \x03(\tR\x06states\x12\x18\n" +
 	"\aactions\x18\x02 \x03(\tR\aactions\x12\x1a\n" +
-	"\bprovider\x18\x03 \x01(\tR\bproviderB\x06Z\x04./pbb\x06proto3"
+	"\bprovider\x18\x03 \x01(\tR\bprovider\x12'\n" +
+	"\x05stats\x18\x04 \x01(\v2\x11.pb.ProviderStatsR\x05stats\x12(\n" +
+	"\x10min_query_length\x18\x05 \x01(\x05R\x0eminQueryLength\x12\x1f\n" +
+	"\vdebounce_ms\x18\x06 \x01(\x05R\n" +
+	"debounceMs\"\x93\x01\n" +
+	"\rProviderStats\x12\x18\n" +
+	"\aqueries\x18\x01 \x01(\x04R\aqueries\x12\x15\n" +
+	"\x06p50_us\x18\x02 \x01(\x03R\x05p50Us\x12\x15\n" +
+	"\x06p95_us\x18\x03 \x01(\x03R\x05p95Us\x12\x17\n" +
+	"\alast_us\x18\x04 \x01(\x03R\x06lastUs\x12!\n" +
+	"\flast_results\x18\x05 \x01(\x05R\vlastResultsB\x06Z\x04./pbb\x06proto3"
 
 var (
 	file_providerstate_proto_rawDescOnce sync.Once
@@ -149,17 +259,19 @@ func file_providerstate_proto_rawDescGZIP() []byte {
 	return file_providerstate_proto_rawDescData
 }
 
-var file_providerstate_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_providerstate_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_providerstate_proto_goTypes = []any{
 	(*ProviderStateRequest)(nil),  // 0: pb.ProviderStateRequest
 	(*ProviderStateResponse)(nil), // 1: pb.ProviderStateResponse
+	(*ProviderStats)(nil),         // 2: pb.ProviderStats
 }
 var file_providerstate_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2, // 0: pb.ProviderStateResponse.stats:type_name -> pb.ProviderStats
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_providerstate_proto_init() }
@@ -173,7 +285,7 @@ func file_providerstate_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_providerstate_proto_rawDesc), len(file_providerstate_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -73,6 +73,8 @@ type QueryRequest struct {
 	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
 	Maxresults    int32                  `protobuf:"varint,3,opt,name=maxresults,proto3" json:"maxresults,omitempty"`
 	Exactsearch   bool                   `protobuf:"varint,4,opt,name=exactsearch,proto3" json:"exactsearch,omitempty"`
+	Stream        bool                   `protobuf:"varint,5,opt,name=stream,proto3" json:"stream,omitempty"`
+	Combined      bool                   `protobuf:"varint,6,opt,name=combined,proto3" json:"combined,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -135,11 +137,26 @@ func (x *QueryRequest) GetExactsearch() bool {
 	return false
 }
 
+func (x *QueryRequest) GetStream() bool {
+	if x != nil {
+		return x.Stream
+	}
+	return false
+}
+
+func (x *QueryRequest) GetCombined() bool {
+	if x != nil {
+		return x.Combined
+	}
+	return false
+}
+
 type QueryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
 	Item          *QueryResponse_Item    `protobuf:"bytes,2,opt,name=item,proto3" json:"item,omitempty"`
 	Qid           int32                  `protobuf:"varint,3,opt,name=qid,proto3" json:"qid,omitempty"`
+	Timedout      []string               `protobuf:"bytes,4,rep,name=timedout,proto3" json:"timedout,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -195,6 +212,13 @@ func (x *QueryResponse) GetQid() int32 {
 	return 0
 }
 
+func (x *QueryResponse) GetTimedout() []string {
+	if x != nil {
+		return x.Timedout
+	}
+	return nil
+}
+
 type QueryResponse_Item struct {
 	state         protoimpl.MessageState        `protogen:"open.v1"`
 	Identifier    string                        `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
@@ -210,6 +234,8 @@ type QueryResponse_Item struct {
 	PreviewType   string                        `protobuf:"bytes,11,opt,name=preview_type,json=previewType,proto3" json:"preview_type,omitempty"`
 	State         []string                      `protobuf:"bytes,12,rep,name=state,proto3" json:"state,omitempty"`
 	Actions       []string                      `protobuf:"bytes,13,rep,name=actions,proto3" json:"actions,omitempty"`
+	DedupKey      string                        `protobuf:"bytes,14,opt,name=dedup_key,json=dedupKey,proto3" json:"dedup_key,omitempty"`
+	Metadata      map[string]string             `protobuf:"bytes,15,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -335,6 +361,20 @@ func (x *QueryResponse_Item) GetActions() []string {
 	return nil
 }
 
+func (x *QueryResponse_Item) GetDedupKey() string {
+	if x != nil {
+		return x.DedupKey
+	}
+	return ""
+}
+
+func (x *QueryResponse_Item) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
 type QueryResponse_Item_FuzzyInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Start         int32                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
@@ -399,18 +439,21 @@ var File_query_proto protoreflect.FileDescriptor
 
 const file_query_proto_rawDesc = "" +
 	"\n" +
-	"\vquery.proto\x12\x02pb\"\x84\x01\n" +
+	"\vquery.proto\x12\x02pb\"\xb8\x01\n" +
 	"\fQueryRequest\x12\x1c\n" +
 	"\tproviders\x18\x01 \x03(\tR\tproviders\x12\x14\n" +
 	"\x05query\x18\x02 \x01(\tR\x05query\x12\x1e\n" +
 	"\n" +
 	"maxresults\x18\x03 \x01(\x05R\n" +
 	"maxresults\x12 \n" +
-	"\vexactsearch\x18\x04 \x01(\bR\vexactsearch\"\xeb\x04\n" +
+	"\vexactsearch\x18\x04 \x01(\bR\vexactsearch\x12\x16\n" +
+	"\x06stream\x18\x05 \x01(\bR\x06stream\x12\x1a\n" +
+	"\bcombined\x18\x06 \x01(\bR\bcombined\"\xa3\x06\n" +
 	"\rQueryResponse\x12\x14\n" +
 	"\x05query\x18\x01 \x01(\tR\x05query\x12*\n" +
 	"\x04item\x18\x02 \x01(\v2\x16.pb.QueryResponse.ItemR\x04item\x12\x10\n" +
-	"\x03qid\x18\x03 \x01(\x05R\x03qid\x1a\xe6\x03\n" +
+	"\x03qid\x18\x03 \x01(\x05R\x03qid\x12\x1a\n" +
+	"\btimedout\x18\x04 \x03(\tR\btimedout\x1a\x82\x05\n" +
 	"\x04Item\x12\x1e\n" +
 	"\n" +
 	"identifier\x18\x01 \x01(\tR\n" +
@@ -427,11 +470,16 @@ const file_query_proto_rawDesc = "" +
 	" \x01(\tR\apreview\x12!\n" +
 	"\fpreview_type\x18\v \x01(\tR\vpreviewType\x12\x14\n" +
 	"\x05state\x18\f \x03(\tR\x05state\x12\x18\n" +
-	"\aactions\x18\r \x03(\tR\aactions\x1aU\n" +
+	"\aactions\x18\r \x03(\tR\aactions\x12\x1b\n" +
+	"\tdedup_key\x18\x0e \x01(\tR\bdedupKey\x12@\n" +
+	"\bmetadata\x18\x0f \x03(\v2$.pb.QueryResponse.Item.MetadataEntryR\bmetadata\x1aU\n" +
 	"\tFuzzyInfo\x12\x14\n" +
 	"\x05start\x18\x01 \x01(\x05R\x05start\x12\x14\n" +
 	"\x05field\x18\x02 \x01(\tR\x05field\x12\x1c\n" +
-	"\tpositions\x18\x03 \x03(\x05R\tpositions\"\x1d\n" +
+	"\tpositions\x18\x03 \x03(\x05R\tpositions\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x1d\n" +
 	"\x04Type\x12\v\n" +
 	"\aREGULAR\x10\x00\x12\b\n" +
 	"\x04FILE\x10\x01B\x06Z\x04./pbb\x06proto3"
@@ -449,23 +497,25 @@ func file_query_proto_rawDescGZIP() []byte {
 }
 
 var file_query_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_query_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_query_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_query_proto_goTypes = []any{
 	(QueryResponse_Type)(0),              // 0: pb.QueryResponse.Type
 	(*QueryRequest)(nil),                 // 1: pb.QueryRequest
 	(*QueryResponse)(nil),                // 2: pb.QueryResponse
 	(*QueryResponse_Item)(nil),           // 3: pb.QueryResponse.Item
 	(*QueryResponse_Item_FuzzyInfo)(nil), // 4: pb.QueryResponse.Item.FuzzyInfo
+	nil,                                  // 5: pb.QueryResponse.Item.MetadataEntry
 }
 var file_query_proto_depIdxs = []int32{
 	3, // 0: pb.QueryResponse.item:type_name -> pb.QueryResponse.Item
 	4, // 1: pb.QueryResponse.Item.fuzzyinfo:type_name -> pb.QueryResponse.Item.FuzzyInfo
 	0, // 2: pb.QueryResponse.Item.type:type_name -> pb.QueryResponse.Type
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	5, // 3: pb.QueryResponse.Item.metadata:type_name -> pb.QueryResponse.Item.MetadataEntry
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_query_proto_init() }
@@ -479,7 +529,7 @@ func file_query_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_query_proto_rawDesc), len(file_query_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   4,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
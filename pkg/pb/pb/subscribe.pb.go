@@ -83,6 +83,7 @@ func (x *SubscribeRequest) GetQuery() string {
 
 type SubscribeResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
 	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -118,6 +119,13 @@ func (*SubscribeResponse) Descriptor() ([]byte, []int) {
 	return file_subscribe_proto_rawDescGZIP(), []int{1}
 }
 
+func (x *SubscribeResponse) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
 func (x *SubscribeResponse) GetValue() string {
 	if x != nil {
 		return x.Value
@@ -133,8 +141,9 @@ const file_subscribe_proto_rawDesc = "" +
 	"\x10SubscribeRequest\x12\x1a\n" +
 	"\binterval\x18\x01 \x01(\x05R\binterval\x12\x1a\n" +
 	"\bprovider\x18\x02 \x01(\tR\bprovider\x12\x14\n" +
-	"\x05query\x18\x03 \x01(\tR\x05query\")\n" +
-	"\x11SubscribeResponse\x12\x14\n" +
+	"\x05query\x18\x03 \x01(\tR\x05query\"E\n" +
+	"\x11SubscribeResponse\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05valueB\x06Z\x04./pbb\x06proto3"
 
 var (